@@ -0,0 +1,107 @@
+package client
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"mintscan/models"
+
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+)
+
+// tokenCacheDuration is how long the token list is cached, which is also how long
+// it takes for a newly issued token to be found by Token
+const tokenCacheDuration = 10 * time.Minute
+
+// statusMaxAge is how long the last status returned by the node is used by LastLatestBlockHeight
+const statusMaxAge = 2 * time.Minute
+
+// validatorCacheDuration is how long the validator set is cached for lookups such as moniker search
+const validatorCacheDuration = time.Minute
+
+// cache keeps a value requested from upstream until it expires. It is shared by copies of a client
+// made by WithContext, so that every handler of a network uses the same cached value
+type cache struct {
+	mu      sync.Mutex
+	value   interface{}
+	expires time.Time
+}
+
+// get returns the cached value or, when it has expired, requests the value and caches it for the given duration.
+// Concurrent callers wait for a single request rather than making their own
+func (c *cache) get(d time.Duration, request func() (interface{}, error)) (interface{}, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.value != nil && time.Now().Before(c.expires) {
+		return c.value, nil
+	}
+
+	value, err := request()
+	if err != nil {
+		return nil, err
+	}
+
+	c.value = value
+	c.expires = time.Now().Add(d)
+
+	return value, nil
+}
+
+// set caches the value for the given duration
+func (c *cache) set(value interface{}, d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.value = value
+	c.expires = time.Now().Add(d)
+}
+
+// peek returns the cached value without requesting it, which is nil when the value has expired
+func (c *cache) peek() interface{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.value == nil || time.Now().After(c.expires) {
+		return nil
+	}
+
+	return c.value
+}
+
+// CachedTokens returns every token in active chain, which is cached for tokenCacheDuration
+func (c Client) CachedTokens() ([]*models.Token, error) {
+	value, err := c.tokens.get(tokenCacheDuration, func() (interface{}, error) {
+		return c.AllTokens()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return value.([]*models.Token), nil
+}
+
+// CachedValidators returns validators in active chain, which are cached for validatorCacheDuration
+func (c Client) CachedValidators() ([]*models.Validator, error) {
+	value, err := c.validators.get(validatorCacheDuration, func() (interface{}, error) {
+		return c.Validators()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return value.([]*models.Validator), nil
+}
+
+// LastLatestBlockHeight returns the latest block height of the last status returned by the node without
+// requesting it, for readers such as metrics scrapes that must not make requests. Status is requested
+// regularly by the exporter, and it fails when no status is returned within statusMaxAge
+func (c Client) LastLatestBlockHeight() (int64, error) {
+	status, ok := c.status.peek().(*ctypes.ResultStatus)
+	if !ok {
+		return -1, fmt.Errorf("no status is returned by the node within %s", statusMaxAge)
+	}
+
+	return status.SyncInfo.LatestBlockHeight, nil
+}
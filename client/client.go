@@ -1,13 +1,18 @@
 package client
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"sort"
 	"strconv"
 	"time"
 
 	"github.com/binance-chain/go-sdk/client/rpc"
 
+	"github.com/tendermint/tendermint/libs/log"
+
 	ctypes "github.com/tendermint/tendermint/rpc/core/types"
 
 	"mintscan/codec"
@@ -31,30 +36,36 @@ type Client struct {
 	explorerClient    *resty.Client
 	rpcClient         rpc.Client
 	lcdClient         *resty.Client
+	l                 log.Logger
+	network           string
+	ctx               context.Context // context of upstream calls, see WithContext
+	tokens            *cache
+	validators        *cache
+	status            *cache // the last status returned by the node, see LastLatestBlockHeight
 }
 
-// NewClient creates a new client with the given config
-func NewClient(cfg config.NodeConfig, marketCfg config.MarketConfig) *Client {
+// NewClient creates a new client of the named network with the given config
+func NewClient(l log.Logger, network string, cfg config.NodeConfig, marketCfg config.MarketConfig) *Client {
 
-	acceleratedClient := resty.New().
+	acceleratedClient := observeHTTP(l, network, resty.New()).
 		SetHostURL(cfg.AcceleratedNode).
 		SetTimeout(30 * time.Second)
 
-	apiClient := resty.New().
+	apiClient := observeHTTP(l, network, resty.New()).
 		SetHostURL(cfg.APIServerEndpoint).
 		SetTimeout(30 * time.Second)
 
-	coinGeckoClient := resty.New().
+	coinGeckoClient := observeHTTP(l, network, resty.New()).
 		SetHostURL(marketCfg.CoinGeckoEndpoint).
 		SetTimeout(30 * time.Second)
 
-	explorerClient := resty.New().
+	explorerClient := observeHTTP(l, network, resty.New()).
 		SetHostURL(cfg.ExplorerServerEndpoint).
 		SetTimeout(50 * time.Second)
 
 	rpcClient := rpc.NewRPCClient(cfg.RPCNode, cfg.NetworkType)
 
-	lcdClient := resty.New().
+	lcdClient := observeHTTP(l, network, resty.New()).
 		SetHostURL(cfg.APIServerEndpoint).
 		SetTimeout(30 * time.Second)
 
@@ -66,22 +77,120 @@ func NewClient(cfg config.NodeConfig, marketCfg config.MarketConfig) *Client {
 		explorerClient,
 		rpcClient,
 		lcdClient,
+		l,
+		network,
+		nil,
+		&cache{},
+		&cache{},
+		&cache{},
 	}
 }
 
-// Status returns status info on the active chain
+// Status returns status info on the active chain. The RPC client doesn't take a context, so Status returns
+// when the context of the client is done while the request keeps running until the RPC client times out
 func (c Client) Status() (*ctypes.ResultStatus, error) {
-	return c.rpcClient.Status()
+	type result struct {
+		status *ctypes.ResultStatus
+		err    error
+	}
+
+	start := time.Now()
+	done := make(chan result, 1)
+
+	go func() {
+		status, err := c.rpcClient.Status()
+		done <- result{status, err}
+	}()
+
+	var res result
+	select {
+	case res = <-done:
+	case <-c.context().Done():
+		res.err = c.context().Err()
+	}
+
+	c.observeRPC("Status", start, res.err)
+	if res.err == nil {
+		c.status.set(res.status, statusMaxAge)
+	}
+	return res.status, res.err
+}
+
+// CheckChainID returns an error if the node is not running on the given chain id
+func (c Client) CheckChainID(chainID string) error {
+	status, err := c.Status()
+	if err != nil {
+		return err
+	}
+
+	if status.NodeInfo.Network != chainID {
+		return fmt.Errorf("node is running on chain %s, but %s is configured", status.NodeInfo.Network, chainID)
+	}
+
+	return nil
+}
+
+// upstreams returns upstream API clients keyed by name
+func (c Client) upstreams() map[string]*resty.Client {
+	return map[string]*resty.Client{
+		"accelerated": c.acceleratedClient,
+		"api":         c.apiClient,
+		"coingecko":   c.coinGeckoClient,
+		"explorer":    c.explorerClient,
+		"lcd":         c.lcdClient,
+	}
+}
+
+// UpstreamNames returns names of upstream APIs other than the Tendermint RPC node in sorted order
+func (c Client) UpstreamNames() []string {
+	names := make([]string, 0)
+	for name := range c.upstreams() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// PingUpstream requests the base url of the named upstream API. An error is returned
+// if the upstream doesn't exist, is unreachable or responds with 5xx status
+func (c Client) PingUpstream(name string) error {
+	rc, ok := c.upstreams()[name]
+	if !ok {
+		return fmt.Errorf("upstream %s does not exist", name)
+	}
+
+	resp, err := c.request(rc, "PingUpstream").Get("")
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode() >= http.StatusInternalServerError {
+		return fmt.Errorf("failed to respond: %s", resp.Status())
+	}
+
+	return nil
 }
 
 // Block queries for a block by height. An error is returned if the query fails.
 func (c Client) Block(height int64) (*tmctypes.ResultBlock, error) {
-	return c.rpcClient.Block(&height)
+	start := time.Now()
+	block, err := c.rpcClient.Block(&height)
+	c.observeRPC("Block", start, err)
+	return block, err
+}
+
+// BlockResults queries for results of transactions in a block by height. An error is returned if the query fails.
+func (c Client) BlockResults(height int64) (*rpc.ResultBlockResults, error) {
+	start := time.Now()
+	results, err := c.rpcClient.BlockResults(&height)
+	c.observeRPC("BlockResults", start, err)
+	return results, err
 }
 
 // LatestBlockHeight returns the latest block height on the active chain
 func (c Client) LatestBlockHeight() (int64, error) {
-	status, err := c.rpcClient.Status()
+	status, err := c.Status()
 	if err != nil {
 		return -1, err
 	}
@@ -91,7 +200,7 @@ func (c Client) LatestBlockHeight() (int64, error) {
 
 // Tokens returns information about existing tokens in active chain
 func (c Client) Tokens(limit int, offset int) ([]*models.Token, error) {
-	resp, err := c.apiClient.R().Get("/tokens?limit=" + strconv.Itoa(limit) + "&offset=" + strconv.Itoa(offset))
+	resp, err := c.request(c.apiClient, "Tokens").Get("/tokens?limit=" + strconv.Itoa(limit) + "&offset=" + strconv.Itoa(offset))
 	if err != nil {
 		return nil, err
 	}
@@ -108,13 +217,16 @@ func (c Client) Tokens(limit int, offset int) ([]*models.Token, error) {
 // ValidatorSet returns all the known Tendermint validators for a given block
 // height. An error is returned if the query fails.
 func (c Client) ValidatorSet(height int64) (*tmctypes.ResultValidators, error) {
-	return c.rpcClient.Validators(&height)
+	start := time.Now()
+	validators, err := c.rpcClient.Validators(&height)
+	c.observeRPC("ValidatorSet", start, err)
+	return validators, err
 }
 
 // Validators returns validators detail information in Tendemrint validators in active chain
 // An error is returns if the query fails.
 func (c Client) Validators() ([]*models.Validator, error) {
-	resp, err := c.apiClient.R().Get("/stake/validators")
+	resp, err := c.request(c.apiClient, "Validators").Get("/stake/validators")
 	if err != nil {
 		return nil, err
 	}
@@ -129,7 +241,7 @@ func (c Client) Validators() ([]*models.Validator, error) {
 }
 
 func (c Client) Validator(address string) (*models.Validator, error) {
-	resp, err := c.apiClient.R().Get(fmt.Sprintf("/stake/validators/%s", address))
+	resp, err := c.request(c.apiClient, "Validator").Get(fmt.Sprintf("/stake/validators/%s", address))
 	if err != nil {
 		return nil, err
 	}
@@ -145,7 +257,7 @@ func (c Client) Validator(address string) (*models.Validator, error) {
 func (c Client) CoinMarketData(id string) (models.CoinGeckoMarket, error) {
 	queryStr := "/coins/" + id + "?localization=false&tickers=false&community_data=false&developer_data=false&sparkline=false"
 
-	resp, err := c.coinGeckoClient.R().Get(queryStr)
+	resp, err := c.request(c.coinGeckoClient, "CoinMarketData").Get(queryStr)
 	if err != nil {
 		return models.CoinGeckoMarket{}, err
 	}
@@ -167,7 +279,7 @@ func (c Client) CoinMarketData(id string) (models.CoinGeckoMarket, error) {
 func (c Client) CoinMarketChartData(id string, from string, to string) (models.CoinGeckoMarketChart, error) {
 	queryStr := "/coins/" + id + "/market_chart/range?id=" + id + "&vs_currency=usd&from=" + from + "&to=" + to
 
-	resp, err := c.coinGeckoClient.R().Get(queryStr)
+	resp, err := c.request(c.coinGeckoClient, "CoinMarketChartData").Get(queryStr)
 	if err != nil {
 		return models.CoinGeckoMarketChart{}, err
 	}
@@ -187,7 +299,7 @@ func (c Client) CoinMarketChartData(id string, from string, to string) (models.C
 
 // Asset returns particular asset information given an asset name
 func (c Client) Asset(assetName string) (models.Asset, error) {
-	resp, err := c.explorerClient.R().Get("/asset?asset=" + assetName)
+	resp, err := c.request(c.explorerClient, "Asset").Get("/asset?asset=" + assetName)
 	if err != nil {
 		return models.Asset{}, err
 	}
@@ -204,7 +316,7 @@ func (c Client) Asset(assetName string) (models.Asset, error) {
 // Assets returns information of all assets existing in an active chain
 func (c Client) Assets(page int, rows int) (models.AssetInfo, error) {
 	queryStr := "/assets?page=" + strconv.Itoa(page) + "&rows=" + strconv.Itoa(rows)
-	resp, err := c.explorerClient.R().Get(queryStr)
+	resp, err := c.request(c.explorerClient, "Assets").Get(queryStr)
 	if err != nil {
 		return models.AssetInfo{}, err
 	}
@@ -221,7 +333,7 @@ func (c Client) Assets(page int, rows int) (models.AssetInfo, error) {
 // AssetHolders returns all asset holders information based upon params
 func (c Client) AssetHolders(asset string, page int, rows int) (models.AssetHolders, error) {
 	queryStr := "/asset-holders?asset=" + asset + "&page=" + strconv.Itoa(page) + "&rows=" + strconv.Itoa(rows)
-	resp, err := c.explorerClient.R().Get(queryStr)
+	resp, err := c.request(c.explorerClient, "AssetHolders").Get(queryStr)
 	if err != nil {
 		return models.AssetHolders{}, err
 	}
@@ -238,7 +350,7 @@ func (c Client) AssetHolders(asset string, page int, rows int) (models.AssetHold
 // AssetTxs returns asset transactions given an asset name based upon params
 func (c Client) AssetTxs(txAsset string, page int, rows int) (models.AssetTxs, error) {
 	queryStr := "/txs?txAsset=" + txAsset + "&page=" + strconv.Itoa(page) + "&rows=" + strconv.Itoa(rows)
-	resp, err := c.explorerClient.R().Get(queryStr)
+	resp, err := c.request(c.explorerClient, "AssetTxs").Get(queryStr)
 	if err != nil {
 		return models.AssetTxs{}, err
 	}
@@ -254,7 +366,7 @@ func (c Client) AssetTxs(txAsset string, page int, rows int) (models.AssetTxs, e
 
 // Account returns account information given an account address
 func (c Client) Account(address string) (models.Account, error) {
-	resp, err := c.apiClient.R().Get("/accounts/" + address)
+	resp, err := c.request(c.apiClient, "Account").Get("/accounts/" + address)
 	if err != nil {
 		return models.Account{}, err
 	}
@@ -271,7 +383,7 @@ func (c Client) Account(address string) (models.Account, error) {
 // AccountTxs retuns tranctions involving in an account based upon params
 func (c Client) AccountTxs(address string, page int, rows int) (models.AccountTxs, error) {
 	queryStr := "/account/txs?address=" + address + "&page=" + strconv.Itoa(page) + "&rows=" + strconv.Itoa(rows)
-	resp, err := c.explorerClient.R().Get(queryStr)
+	resp, err := c.request(c.explorerClient, "AccountTxs").Get(queryStr)
 	if err != nil {
 		return models.AccountTxs{}, err
 	}
@@ -287,7 +399,7 @@ func (c Client) AccountTxs(address string, page int, rows int) (models.AccountTx
 
 // Order returns order information given an order id
 func (c Client) Order(id string) (models.Order, error) {
-	resp, err := c.acceleratedClient.R().Get("/orders/" + id)
+	resp, err := c.request(c.acceleratedClient, "Order").Get("/orders/" + id)
 	if err != nil {
 		return models.Order{}, err
 	}
@@ -303,7 +415,7 @@ func (c Client) Order(id string) (models.Order, error) {
 
 // TxMsgFees returns fees for different transaciton message types
 func (c Client) TxMsgFees() ([]*models.TxMsgFee, error) {
-	resp, err := c.acceleratedClient.R().Get("/fees")
+	resp, err := c.request(c.acceleratedClient, "TxMsgFees").Get("/fees")
 	if err != nil {
 		return []*models.TxMsgFee{}, err
 	}
@@ -318,7 +430,7 @@ func (c Client) TxMsgFees() ([]*models.TxMsgFee, error) {
 }
 
 func (c Client) Txs(before, after, limit int) ([]models.TxData, int) {
-	resp, err := c.apiClient.R().Get(fmt.Sprintf("/txs?before=%d&after=%d&limit=%d", before, after, limit))
+	resp, err := c.request(c.apiClient, "Txs").Get(fmt.Sprintf("/txs?before=%d&after=%d&limit=%d", before, after, limit))
 	if err != nil {
 		return []models.TxData{}, 0
 	}
@@ -334,7 +446,7 @@ func (c Client) Txs(before, after, limit int) ([]models.TxData, int) {
 
 func (c Client) TxByHash(hash string) (models.TxData, error) {
 	var tx = models.TxData{}
-	resp, err := c.apiClient.R().Get(fmt.Sprintf("/tx?hash=%s", hash))
+	resp, err := c.request(c.apiClient, "TxByHash").Get(fmt.Sprintf("/tx?hash=%s", hash))
 	if err != nil {
 		return tx, err
 	}
@@ -347,7 +459,7 @@ func (c Client) TxByHash(hash string) (models.TxData, error) {
 }
 
 func (c Client) TxsByTypeAndTime(typo string, startTime int64, endTime int64, before, after, limit int) ([]models.TxData, int) {
-	resp, err := c.apiClient.R().Get(fmt.Sprintf("/txs?type=%s&starttime=%d&endtime=%d&before=%d&after=%d&limit=%d",
+	resp, err := c.request(c.apiClient, "TxsByTypeAndTime").Get(fmt.Sprintf("/txs?type=%s&starttime=%d&endtime=%d&before=%d&after=%d&limit=%d",
 		typo, startTime, endTime, before, after, limit))
 	if err != nil {
 		return []models.TxData{}, 0
@@ -363,7 +475,7 @@ func (c Client) TxsByTypeAndTime(typo string, startTime int64, endTime int64, be
 }
 
 func (c Client) Blocks(before, after, limit int) ([]models.BlockData, error) {
-	resp, err := c.apiClient.R().Get(fmt.Sprintf("/blocks?before=%d&after=%d&limit=%d", before, after, limit))
+	resp, err := c.request(c.apiClient, "Blocks").Get(fmt.Sprintf("/blocks?before=%d&after=%d&limit=%d", before, after, limit))
 	if err != nil {
 		return []models.BlockData{}, err
 	}
@@ -374,7 +486,7 @@ func (c Client) Blocks(before, after, limit int) ([]models.BlockData, error) {
 }
 
 func (c Client) LastBlockHeight() (int64, error) {
-	resp, err := c.apiClient.R().Get("blocks/latest")
+	resp, err := c.request(c.apiClient, "LastBlockHeight").Get("blocks/latest")
 	if err != nil {
 		return 0, err
 	}
@@ -383,3 +495,170 @@ func (c Client) LastBlockHeight() (int64, error) {
 	err = json.Unmarshal(resp.Body(), &ret)
 	return ret.Height, err
 }
+
+// OrderTrades returns trades that filled an order given an owner address, order id and order side
+func (c Client) OrderTrades(address string, orderID string, side int) ([]models.Trade, error) {
+	queryStr := "/trades?address=" + address + "&limit=1000"
+	if side == models.OrderSideBuy {
+		queryStr += "&buyerOrderId=" + orderID
+	} else {
+		queryStr += "&sellerOrderId=" + orderID
+	}
+
+	resp, err := c.request(c.apiClient, "OrderTrades").Get(queryStr)
+	if err != nil {
+		return []models.Trade{}, err
+	}
+
+	var trades models.Trades
+	err = json.Unmarshal(resp.Body(), &trades)
+	if err != nil {
+		return []models.Trade{}, err
+	}
+
+	return trades.Trade, nil
+}
+
+// Trades returns trades executed since start time in milliseconds based upon params
+func (c Client) Trades(start int64, limit int, offset int) (models.Trades, error) {
+	queryStr := fmt.Sprintf("/trades?start=%d&limit=%d&offset=%d", start, limit, offset)
+	resp, err := c.request(c.apiClient, "Trades").Get(queryStr)
+	if err != nil {
+		return models.Trades{}, err
+	}
+
+	var trades models.Trades
+	err = json.Unmarshal(resp.Body(), &trades)
+	if err != nil {
+		return models.Trades{}, err
+	}
+
+	return trades, nil
+}
+
+// AllAssetHolders returns quantities held by holders of an asset up to maxPages pages of 100 holders
+// along with the total number of holders, which may be more than the returned quantities
+func (c Client) AllAssetHolders(asset string, maxPages int) ([]float64, int, error) {
+	rows := 100
+	total := 0
+	quantities := make([]float64, 0)
+
+	for page := 1; page <= maxPages; page++ {
+		assetHolders, err := c.AssetHolders(asset, page, rows)
+		if err != nil {
+			return quantities, total, err
+		}
+
+		total = assetHolders.TotalNum
+
+		for _, holder := range assetHolders.AddressHolders {
+			quantities = append(quantities, holder.Quantity)
+		}
+
+		if len(assetHolders.AddressHolders) < rows || len(quantities) >= assetHolders.TotalNum {
+			break
+		}
+	}
+
+	return quantities, total, nil
+}
+
+// AssetDistribution computes holder distribution of an asset from its top holders up to maxPages pages
+// of 100 holders. Top holders share is computed against total supply of the asset when it is known
+func (c Client) AssetDistribution(asset string, maxPages int) (models.AssetDistribution, error) {
+	quantities, total, err := c.AllAssetHolders(asset, maxPages)
+	if err != nil {
+		return models.AssetDistribution{}, err
+	}
+
+	var supply float64
+	if token, err := c.Token(asset); err == nil {
+		supply, _ = strconv.ParseFloat(token.TotalSupply, 64)
+	}
+
+	return models.NewAssetDistribution(asset, quantities, total, supply), nil
+}
+
+// Token returns token information given a token symbol from the cached token list.
+// An error is returned if the token does not exist
+func (c Client) Token(symbol string) (*models.Token, error) {
+	tokens, err := c.CachedTokens()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, token := range tokens {
+		if token.Symbol == symbol {
+			return token, nil
+		}
+	}
+
+	return nil, fmt.Errorf("token %s does not exist", symbol)
+}
+
+// AllTokens returns information about every existing token in active chain
+func (c Client) AllTokens() ([]*models.Token, error) {
+	limit := 1000
+	result := make([]*models.Token, 0)
+
+	for offset := 0; ; offset += limit {
+		tokens, err := c.Tokens(limit, offset)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, tokens...)
+
+		if len(tokens) < limit {
+			return result, nil
+		}
+	}
+}
+
+// Proposals returns governance proposals in active chain
+func (c Client) Proposals() ([]models.Proposal, error) {
+	resp, err := c.request(c.lcdClient, "Proposals").Get("/gov/proposals")
+	if err != nil {
+		return []models.Proposal{}, err
+	}
+
+	if resp.IsError() {
+		return []models.Proposal{}, fmt.Errorf("failed to respond: %s", resp.Status())
+	}
+
+	var proposals []models.Proposal
+	err = json.Unmarshal(resp.Body(), &proposals)
+	if err != nil {
+		return []models.Proposal{}, err
+	}
+
+	return proposals, nil
+}
+
+// Proposal returns governance proposal given a proposal id. It returns nil if the proposal doesn't exist
+func (c Client) Proposal(id int64) (*models.Proposal, error) {
+	resp, err := c.request(c.lcdClient, "Proposal").Get(fmt.Sprintf("/gov/proposals/%d", id))
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode() == http.StatusNotFound {
+		return nil, nil
+	}
+
+	if resp.IsError() {
+		return nil, fmt.Errorf("failed to respond: %s", resp.Status())
+	}
+
+	var proposal *models.Proposal
+	err = json.Unmarshal(resp.Body(), &proposal)
+	if err != nil {
+		return nil, err
+	}
+
+	if proposal != nil && proposal.Value.ProposalID != id {
+		return nil, nil
+	}
+
+	return proposal, nil
+}
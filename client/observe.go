@@ -0,0 +1,178 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"mintscan/metrics"
+	"mintscan/tracing"
+
+	"github.com/tendermint/tendermint/libs/log"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	resty "github.com/go-resty/resty/v2"
+)
+
+// Call is an upstream call made through the client
+type Call struct {
+	Method   string
+	Duration time.Duration
+	Err      error
+}
+
+// CallLog collects upstream calls made with a context
+type CallLog struct {
+	mu    sync.Mutex
+	calls []Call
+}
+
+type contextKey int
+
+const (
+	callLogKey contextKey = iota
+	methodKey
+)
+
+// WithCallLog returns a copy of the context that collects upstream calls made with it
+func WithCallLog(ctx context.Context) (context.Context, *CallLog) {
+	cl := &CallLog{}
+	return context.WithValue(ctx, callLogKey, cl), cl
+}
+
+// Calls returns the upstream calls collected so far
+func (cl *CallLog) Calls() []Call {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	return append([]Call(nil), cl.calls...)
+}
+
+func (cl *CallLog) add(call Call) {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	cl.calls = append(cl.calls, call)
+}
+
+// WithContext returns a copy of the client whose upstream calls are made with the given context,
+// so that they are cancelled with it and collected to its call log
+func (c *Client) WithContext(ctx context.Context) *Client {
+	cc := *c
+	cc.ctx = ctx
+	return &cc
+}
+
+func (c Client) context() context.Context {
+	if c.ctx == nil {
+		return context.Background()
+	}
+
+	return c.ctx
+}
+
+// request creates a request of an upstream API client on behalf of the named client method
+func (c Client) request(rc *resty.Client, method string) *resty.Request {
+	return rc.R().SetContext(context.WithValue(c.context(), methodKey, method))
+}
+
+// observeRPC observes a call to the Tendermint RPC node. Its span is recorded after the fact
+// since the RPC client doesn't take a context
+func (c Client) observeRPC(method string, start time.Time, err error) {
+	end := time.Now()
+
+	_, span := tracing.Tracer().Start(c.context(), "rpc "+method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithTimestamp(start),
+		trace.WithAttributes(attribute.String("network", c.network), attribute.String("rpc.method", method)),
+	)
+	endSpan(span, err, trace.WithTimestamp(end))
+
+	observe(c.l, c.network, c.context(), method, end.Sub(start), err)
+}
+
+// endSpan ends a span of an upstream call, marking it failed when the call failed
+func endSpan(span trace.Span, err error, options ...trace.SpanEndOption) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End(options...)
+}
+
+// observe logs an upstream call, records it to the call log of the context and to metrics
+func observe(l log.Logger, network string, ctx context.Context, method string, duration time.Duration, err error) {
+	metrics.ObserveUpstreamCall(network, method, duration, err)
+
+	if cl, ok := ctx.Value(callLogKey).(*CallLog); ok {
+		cl.add(Call{Method: method, Duration: duration, Err: err})
+	}
+
+	if err != nil {
+		l.Error("upstream call failed", "call", method, "duration", duration, "err", err)
+		return
+	}
+
+	l.Debug("upstream call", "call", method, "duration", duration)
+}
+
+// transport observes HTTP calls made by upstream API clients
+type transport struct {
+	l       log.Logger
+	network string
+	next    http.RoundTripper
+}
+
+// observeHTTP wraps the transport of an upstream API client to observe its calls
+func observeHTTP(l log.Logger, network string, rc *resty.Client) *resty.Client {
+	next := rc.GetClient().Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return rc.SetTransport(&transport{l: l, network: network, next: next})
+}
+
+// RoundTrip implements http.RoundTripper interface. Trace context is propagated to the upstream API
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	method, ok := req.Context().Value(methodKey).(string)
+	if !ok {
+		method = req.URL.Path
+	}
+
+	ctx, span := tracing.Tracer().Start(req.Context(), "http "+method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("network", t.network),
+			attribute.String("http.request.method", req.Method),
+			attribute.String("server.address", req.URL.Host),
+			attribute.String("url.path", req.URL.Path),
+		),
+	)
+
+	req = req.Clone(ctx)
+	tracing.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+
+	callErr := err
+	if err == nil {
+		span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+		if resp.StatusCode >= http.StatusInternalServerError {
+			callErr = fmt.Errorf("%s responded %s", req.URL.Host, resp.Status)
+		}
+	}
+	endSpan(span, callErr)
+
+	observe(t.l, t.network, req.Context(), method, time.Since(start), callErr)
+
+	return resp, err
+}
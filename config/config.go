@@ -1,21 +1,55 @@
 package config
 
 import (
-	"log"
-
-	"github.com/pkg/errors"
+	"fmt"
+	"math"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 
 	cmtypes "github.com/binance-chain/go-sdk/common/types"
 )
 
-// Config wraps all config
+// EnvPrefix is the prefix of environment variables that override config fields.
+// A field is overridden by an environment variable named with its upper-cased key path
+// joined by underscores, e.g. MINTSCAN_MAINNET_DATABASE_PASSWORD for mainnet.database.password,
+// and fields that are not in the file can be set the same way. List fields are comma separated.
+// Networks that are defined only in environment must be named in MINTSCAN_NETWORKS, and rate
+// limit tiers are named only in the file, though their fields can be overridden
+const EnvPrefix = "MINTSCAN"
+
+// Config wraps all config. Config of the active network is embedded
 type Config struct {
-	Node   NodeConfig   `yaml:"node"`
-	DB     DBConfig     `yaml:"database"`
-	Web    WebConfig    `yaml:"web"`
-	Market MarketConfig `yaml:"market"`
+	Active   string                    `yaml:"active"`
+	Log      LogConfig                 `yaml:"log"`
+	Tracing  TracingConfig             `yaml:"tracing"`
+	CORS     CORSConfig                `yaml:"cors"`
+	Networks map[string]*NetworkConfig `yaml:"-"`
+	*NetworkConfig
+}
+
+// LogConfig wraps params for logging, which are shared by all networks
+type LogConfig struct {
+	Level  string `yaml:"level"`  // one of debug, info or error, defaults to info
+	Format string `yaml:"format"` // either logfmt or json, defaults to logfmt
+}
+
+// NetworkConfig wraps all config of a named network
+type NetworkConfig struct {
+	Name      string          `yaml:"-"`
+	Node      NodeConfig      `yaml:"node"`
+	DB        DBConfig        `yaml:"database"`
+	Web       WebConfig       `yaml:"web"`
+	Market    MarketConfig    `yaml:"market"`
+	Asset     AssetConfig     `yaml:"asset"`
+	Status    StatusConfig    `yaml:"status"`
+	Health    HealthConfig    `yaml:"health"`
+	RateLimit RateLimitConfig `yaml:"rate_limit"`
 }
 
 // NodeConfig wraps all node endpoints that are used in this project
@@ -24,7 +58,8 @@ type NodeConfig struct {
 	AcceleratedNode        string               `yaml:"accelerated_node"`
 	APIServerEndpoint      string               `yaml:"api_server_endpoint"`
 	ExplorerServerEndpoint string               `yaml:"explorer_server_endpoint"`
-	NetworkType            cmtypes.ChainNetwork `yaml:"network_type"`
+	NetworkType            cmtypes.ChainNetwork `yaml:"network_type"` // either mainnet or testnet, defaults to mainnet
+	ChainID                string               `yaml:"chain_id"`     // chain id that the node must be running on
 }
 
 // DBConfig wraps all required parameters for database connection
@@ -38,7 +73,10 @@ type DBConfig struct {
 
 // WebConfig wraps all required paramaters for boostraping web server
 type WebConfig struct {
-	Port string `yaml:"port"`
+	Port        string   `yaml:"port"`         // only the port of the active network is listened on
+	MetricsPort string   `yaml:"metrics_port"` // port that metrics are served on apart from the API, metrics are disabled when empty
+	AdminToken  string   `yaml:"admin_token"`  // admin endpoints are disabled when empty
+	Hosts       []string `yaml:"hosts"`        // host names whose requests are routed to the network
 }
 
 // MarketConfig wraps all required params for market endpoints
@@ -46,74 +84,446 @@ type MarketConfig struct {
 	CoinGeckoEndpoint string `yaml:"coingecko_endpoint"`
 }
 
-// ParseConfig attempts to read and parse config.yaml from the given path
-// An error reading or parsing the config results in a panic.
-func ParseConfig() *Config {
-	viper.SetConfigName("config")
-	viper.SetConfigType("yaml")
-	viper.AddConfigPath("./")
-	viper.AddConfigPath("../")                                                 // for test cases
-	viper.AddConfigPath("/home/ubuntu/mintscan-binance-dex-backend/mintscan/") // for production
+// AssetConfig wraps params for asset endpoints
+type AssetConfig struct {
+	FeaturedAssets     []string `yaml:"featured_assets"`
+	DistributionAssets []string `yaml:"distribution_assets"` // assets that holder distribution is tracked for in addition to featured ones
+}
 
-	if err := viper.ReadInConfig(); err != nil {
-		log.Fatal(errors.Wrap(err, "failed to read config"))
-	}
+// StatusConfig wraps params for status endpoint
+type StatusConfig struct {
+	BlockTimeWindow int64 `yaml:"block_time_window"` // number of blocks to average block time over
+}
+
+// TracingConfig wraps params for exporting traces over OTLP, which are shared by all networks
+type TracingConfig struct {
+	Endpoint    string  `yaml:"endpoint"`     // url of OTLP/HTTP collector, tracing is disabled when empty
+	ServiceName string  `yaml:"service_name"` // defaults to mintscan
+	SampleRatio float64 `yaml:"sample_ratio"` // ratio of traces sampled when not decided by the caller, defaults to 1
+}
+
+// CORSConfig wraps params for cross-origin requests, which are shared by all networks
+type CORSConfig struct {
+	AllowedOrigins []string      `yaml:"allowed_origins"` // defaults to *
+	AllowedMethods []string      `yaml:"allowed_methods"` // defaults to GET, POST, PUT, DELETE and OPTIONS
+	AllowedHeaders []string      `yaml:"allowed_headers"` // defaults to headers that are read by this service
+	MaxAge         time.Duration `yaml:"max_age"`         // how long preflight responses are cached, defaults to 10m
+}
+
+// reservedKeys are top-level keys in config file that are not network names
+var reservedKeys = map[string]bool{
+	"active":   true,
+	"networks": true,
+	"log":      true,
+	"tracing":  true,
+	"cors":     true,
+}
+
+// HealthConfig wraps params for readiness checks
+type HealthConfig struct {
+	StaleAfter      time.Duration `yaml:"stale_after"`      // max age of the latest indexed block, defaults to 2m
+	Optional        []string      `yaml:"optional"`         // checks that are reported but don't fail readiness, e.g. coingecko
+	OptionalNetwork bool          `yaml:"optional_network"` // the whole network is reported but doesn't fail readiness
+}
+
+// RateLimitConfig wraps params for rate limiting. Requests without an API key are limited per client IP,
+// and requests with an API key are limited per key by the tier of the key
+type RateLimitConfig struct {
+	IP        RateLimitTier            `yaml:"ip"`
+	Tiers     map[string]RateLimitTier `yaml:"tiers"`
+	ProxyHops int                      `yaml:"proxy_hops"` // number of proxies in front that append to X-Forwarded-For
+}
 
-	cfg := Config{}
+// RateLimitTier defines a token bucket that is refilled at rate tokens per second up to burst tokens.
+// Requests are not limited when rate is 0
+type RateLimitTier struct {
+	Rate  float64 `yaml:"rate"`
+	Burst int     `yaml:"burst"` // defaults to rate rounded up
+}
 
-	if viper.GetString("active") == "" {
-		log.Fatal("define active param in your config file.")
+// ValidationError lists every missing or malformed field in config
+type ValidationError struct {
+	Errors []string
+}
+
+// Error implements error interface
+func (e *ValidationError) Error() string {
+	return "invalid config:\n  " + strings.Join(e.Errors, "\n  ")
+}
+
+// ParseConfig reads and validates config from the given path. When path is empty, it is taken from
+// MINTSCAN_CONFIG environment variable and falls back to config.yaml in the working directory,
+// which may be missing when config is given only in environment.
+// Every network in the file is parsed and the active network is the one named by the active field.
+func ParseConfig(path string) (*Config, error) {
+	if path == "" {
+		path = os.Getenv(EnvPrefix + "_CONFIG")
 	}
 
-	switch viper.GetString("active") {
-	case "mainnet":
-		cfg.Node = NodeConfig{
-			RPCNode:                viper.GetString("mainnet.node.rpc_node"),
-			AcceleratedNode:        viper.GetString("mainnet.node.accelerated_node"),
-			APIServerEndpoint:      viper.GetString("mainnet.node.api_server_endpoint"),
-			ExplorerServerEndpoint: viper.GetString("mainnet.node.explorer_server_endpoint"),
-			NetworkType:            cmtypes.ProdNetwork,
-		}
-		cfg.DB = DBConfig{
-			Host:     viper.GetString("mainnet.database.host"),
-			Port:     viper.GetString("mainnet.database.port"),
-			User:     viper.GetString("mainnet.database.user"),
-			Password: viper.GetString("mainnet.database.password"),
-			Table:    viper.GetString("mainnet.database.table"),
-		}
-		cfg.Web = WebConfig{
-			Port: viper.GetString("mainnet.web.port"),
+	explicit := path != ""
+	if !explicit {
+		path = "config.yaml"
+	}
+
+	v := viper.New()
+	v.SetConfigFile(path)
+	v.SetConfigType("yaml")
+	v.SetEnvPrefix(EnvPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+
+	// Viper returns a path error rather than ConfigFileNotFoundError for a missing file set by SetConfigFile
+	if err := v.ReadInConfig(); err != nil {
+		_, notFound := err.(viper.ConfigFileNotFoundError)
+		if explicit || !(notFound || os.IsNotExist(err)) {
+			return nil, fmt.Errorf("failed to read config %s: %s", path, err)
 		}
-		cfg.Market = MarketConfig{
-			viper.GetString("mainnet.market.coingecko_endpoint"),
+	}
+
+	ld := &loader{v: v}
+
+	cfg := &Config{
+		Active: ld.str("active", true),
+		Log: LogConfig{
+			Level:  ld.oneOf("log.level", "info", "debug", "info", "error"),
+			Format: ld.oneOf("log.format", "logfmt", "logfmt", "json"),
+		},
+		Tracing: TracingConfig{
+			Endpoint:    ld.optionalURL("tracing.endpoint"),
+			ServiceName: ld.str("tracing.service_name", false),
+			SampleRatio: ld.ratio("tracing.sample_ratio"),
+		},
+		CORS: CORSConfig{
+			AllowedOrigins: ld.strs("cors.allowed_origins", "*"),
+			AllowedMethods: ld.strs("cors.allowed_methods", "GET", "POST", "PUT", "DELETE", "OPTIONS"),
+			AllowedHeaders: ld.strs("cors.allowed_headers", "Authorization", "Content-Type", "If-None-Match", "X-API-Key", "X-Request-ID"),
+			MaxAge:         ld.duration("cors.max_age", 10*time.Minute),
+		},
+		Networks: make(map[string]*NetworkConfig),
+	}
+
+	if cfg.Tracing.ServiceName == "" {
+		cfg.Tracing.ServiceName = "mintscan"
+	}
+
+	seen := make(map[string]bool)
+	names := make([]string, 0)
+	for key, value := range v.AllSettings() {
+		if _, ok := value.(map[string]interface{}); ok && !reservedKeys[key] {
+			seen[key] = true
+			names = append(names, key)
 		}
+	}
 
-	case "testnet":
-		cfg.Node = NodeConfig{
-			RPCNode:                viper.GetString("testnet.node.rpc_node"),
-			AcceleratedNode:        viper.GetString("testnet.node.accelerated_node"),
-			APIServerEndpoint:      viper.GetString("testnet.node.api_server_endpoint"),
-			ExplorerServerEndpoint: viper.GetString("testnet.node.explorer_server_endpoint"),
-			NetworkType:            cmtypes.ProdNetwork, // temporary
-			// NetworkType:       cmtypes.TestNetwork,
+	// Networks that are defined only in environment are named by networks field
+	for _, name := range ld.strs("networks") {
+		name = strings.ToLower(name)
+		switch {
+		case reservedKeys[name]:
+			ld.errorf("networks: '%s' is a reserved key", name)
+		case !seen[name]:
+			seen[name] = true
+			names = append(names, name)
 		}
-		cfg.DB = DBConfig{
-			Host:     viper.GetString("testnet.database.host"),
-			Port:     viper.GetString("testnet.database.port"),
-			User:     viper.GetString("testnet.database.user"),
-			Password: viper.GetString("testnet.database.password"),
-			Table:    viper.GetString("testnet.database.table"),
+	}
+	sort.Strings(names)
+
+	if len(names) <= 0 {
+		ld.errorf("no network is defined")
+	}
+
+	for _, name := range names {
+		cfg.Networks[name] = ld.network(name, name == cfg.Active)
+	}
+
+	if cfg.Active != "" {
+		active, ok := cfg.Networks[cfg.Active]
+		if !ok {
+			ld.errorf("active: network '%s' is not defined", cfg.Active)
 		}
-		cfg.Web = WebConfig{
-			Port: viper.GetString("testnet.web.port"),
+		cfg.NetworkConfig = active
+	}
+
+	if len(ld.errs) > 0 {
+		return nil, &ValidationError{Errors: ld.errs}
+	}
+
+	return cfg, nil
+}
+
+// loader reads config fields and collects validation errors of them
+type loader struct {
+	v    *viper.Viper
+	errs []string
+}
+
+// network reads config of a named network. Web port is only required for the active network
+func (ld *loader) network(name string, active bool) *NetworkConfig {
+	key := func(field string) string {
+		return name + "." + field
+	}
+
+	port := ld.optionalPort
+	if active {
+		port = ld.port
+	}
+
+	return &NetworkConfig{
+		Name: name,
+		Node: NodeConfig{
+			RPCNode:                ld.url(key("node.rpc_node")),
+			AcceleratedNode:        ld.url(key("node.accelerated_node")),
+			APIServerEndpoint:      ld.url(key("node.api_server_endpoint")),
+			ExplorerServerEndpoint: ld.url(key("node.explorer_server_endpoint")),
+			NetworkType:            ld.networkType(key("node.network_type")),
+			ChainID:                ld.str(key("node.chain_id"), true),
+		},
+		DB: DBConfig{
+			Host:     ld.str(key("database.host"), true),
+			Port:     ld.port(key("database.port")),
+			User:     ld.str(key("database.user"), true),
+			Password: ld.str(key("database.password"), false),
+			Table:    ld.str(key("database.table"), true),
+		},
+		Web: WebConfig{
+			Port:        port(key("web.port")),
+			MetricsPort: ld.optionalPort(key("web.metrics_port")),
+			AdminToken:  ld.str(key("web.admin_token"), false),
+			Hosts:       ld.strs(key("web.hosts")),
+		},
+		Market: MarketConfig{
+			CoinGeckoEndpoint: ld.url(key("market.coingecko_endpoint")),
+		},
+		Asset: AssetConfig{
+			FeaturedAssets:     ld.strs(key("asset.featured_assets")),
+			DistributionAssets: ld.strs(key("asset.distribution_assets")),
+		},
+		Status: StatusConfig{
+			BlockTimeWindow: ld.int64(key("status.block_time_window")),
+		},
+		Health: HealthConfig{
+			StaleAfter:      ld.duration(key("health.stale_after"), 2*time.Minute),
+			Optional:        ld.strs(key("health.optional")),
+			OptionalNetwork: ld.bool(key("health.optional_network")),
+		},
+		RateLimit: ld.rateLimit(key("rate_limit")),
+	}
+}
+
+// rateLimit reads rate limit config whose tiers are keyed by name
+func (ld *loader) rateLimit(key string) RateLimitConfig {
+	cfg := RateLimitConfig{
+		IP:        ld.rateLimitTier(key + ".ip"),
+		Tiers:     make(map[string]RateLimitTier),
+		ProxyHops: int(ld.int64(key + ".proxy_hops")),
+	}
+
+	for name := range ld.v.GetStringMap(key + ".tiers") {
+		cfg.Tiers[name] = ld.rateLimitTier(key + ".tiers." + name)
+	}
+
+	return cfg
+}
+
+// rateLimitTier reads a token bucket of rate limit config
+func (ld *loader) rateLimitTier(key string) RateLimitTier {
+	tier := RateLimitTier{
+		Rate:  ld.float(key + ".rate"),
+		Burst: int(ld.int64(key + ".burst")),
+	}
+
+	if tier.Burst == 0 {
+		tier.Burst = int(math.Ceil(tier.Rate))
+	}
+
+	return tier
+}
+
+// errorf adds a validation error
+func (ld *loader) errorf(format string, args ...interface{}) {
+	ld.errs = append(ld.errs, fmt.Sprintf(format, args...))
+}
+
+// str reads a string field
+func (ld *loader) str(key string, required bool) string {
+	value := strings.TrimSpace(ld.v.GetString(key))
+	if required && value == "" {
+		ld.errorf("%s: is required", key)
+	}
+
+	return value
+}
+
+// strs reads an optional list field. A list that is set in environment is separated by commas
+func (ld *loader) strs(key string, defaultValues ...string) []string {
+	var values []string
+
+	switch value := ld.v.Get(key).(type) {
+	case string:
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				values = append(values, item)
+			}
 		}
-		cfg.Market = MarketConfig{
-			viper.GetString("testnet.market.coingecko_endpoint"),
+	default:
+		values = ld.v.GetStringSlice(key)
+	}
+
+	if len(values) <= 0 {
+		return defaultValues
+	}
+
+	return values
+}
+
+// url reads a required url field
+func (ld *loader) url(key string) string {
+	return ld.parseURL(key, ld.str(key, true))
+}
+
+// optionalURL reads an optional url field
+func (ld *loader) optionalURL(key string) string {
+	return ld.parseURL(key, ld.str(key, false))
+}
+
+func (ld *loader) parseURL(key string, value string) string {
+	if value == "" {
+		return value
+	}
+
+	u, err := url.Parse(value)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		ld.errorf("%s: '%s' is not a valid url", key, value)
+	}
+
+	return value
+}
+
+// port reads a required port field
+func (ld *loader) port(key string) string {
+	return ld.parsePort(key, ld.str(key, true))
+}
+
+// optionalPort reads an optional port field
+func (ld *loader) optionalPort(key string) string {
+	return ld.parsePort(key, ld.str(key, false))
+}
+
+func (ld *loader) parsePort(key string, value string) string {
+	if value == "" {
+		return value
+	}
+
+	port, err := strconv.Atoi(value)
+	if err != nil || port < 1 || port > 65535 {
+		ld.errorf("%s: '%s' is not a valid port", key, value)
+	}
+
+	return value
+}
+
+// int64 reads an optional non-negative integer field
+func (ld *loader) int64(key string) int64 {
+	value := ld.str(key, false)
+	if value == "" {
+		return 0
+	}
+
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n < 0 {
+		ld.errorf("%s: '%s' is not a valid non-negative integer", key, value)
+	}
+
+	return n
+}
+
+// duration reads an optional positive duration field such as 90s or 5m
+func (ld *loader) duration(key string, defaultValue time.Duration) time.Duration {
+	value := ld.str(key, false)
+	if value == "" {
+		return defaultValue
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		ld.errorf("%s: '%s' is not a valid positive duration", key, value)
+		return defaultValue
+	}
+
+	return d
+}
+
+// float reads an optional non-negative number field
+func (ld *loader) float(key string) float64 {
+	value := ld.str(key, false)
+	if value == "" {
+		return 0
+	}
+
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil || f < 0 {
+		ld.errorf("%s: '%s' is not a valid non-negative number", key, value)
+	}
+
+	return f
+}
+
+// bool reads an optional boolean field
+func (ld *loader) bool(key string) bool {
+	value := ld.str(key, false)
+	if value == "" {
+		return false
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		ld.errorf("%s: '%s' is not a valid boolean", key, value)
+	}
+
+	return b
+}
+
+// ratio reads an optional field between 0 and 1, defaulting to 1
+func (ld *loader) ratio(key string) float64 {
+	value := ld.str(key, false)
+	if value == "" {
+		return 1
+	}
+
+	r, err := strconv.ParseFloat(value, 64)
+	if err != nil || r < 0 || r > 1 {
+		ld.errorf("%s: '%s' is not a ratio between 0 and 1", key, value)
+	}
+
+	return r
+}
+
+// oneOf reads an optional field whose value must be one of the given values
+func (ld *loader) oneOf(key string, defaultValue string, values ...string) string {
+	value := strings.ToLower(ld.str(key, false))
+	if value == "" {
+		return defaultValue
+	}
+
+	for _, v := range values {
+		if value == v {
+			return value
 		}
+	}
 
+	ld.errorf("%s: '%s' must be one of %s", key, value, strings.Join(values, ", "))
+	return defaultValue
+}
+
+// networkType reads an optional network type field
+func (ld *loader) networkType(key string) cmtypes.ChainNetwork {
+	switch value := ld.str(key, false); value {
+	case "", "mainnet":
+		return cmtypes.ProdNetwork
+	case "testnet":
+		return cmtypes.TestNetwork
 	default:
-		log.Fatalf("active parameter in config.yaml cannot be set as '%s'", viper.GetString("active"))
+		ld.errorf("%s: '%s' must be either mainnet or testnet", key, value)
+		return cmtypes.ProdNetwork
 	}
-
-	return &cfg
 }
@@ -1,7 +1,10 @@
 package db
 
 import (
+	"context"
+
 	"mintscan/config"
+	"mintscan/schema"
 
 	"github.com/go-pg/pg"
 	"github.com/go-pg/pg/orm"
@@ -26,9 +29,16 @@ func Connect(cfg config.DBConfig) *Database {
 		Database: cfg.Table,
 	})
 
+	db.AddQueryHook(tracingHook{})
+
 	return &Database{db}
 }
 
+// WithContext returns a copy of the database handle whose queries are made with the given context
+func (db *Database) WithContext(ctx context.Context) *Database {
+	return &Database{db.DB.WithContext(ctx)}
+}
+
 // Ping returns a database connection handle
 // An error is returned if the connection fails.
 func (db *Database) Ping() error {
@@ -39,3 +49,35 @@ func (db *Database) Ping() error {
 
 	return nil
 }
+
+// CreateTables creates tables that are managed by this service if they do not exist.
+// Block, transaction, validator and statistics tables are created by the exporter.
+func (db *Database) CreateTables() error {
+	for _, model := range []interface{}{
+		(*schema.Trade)(nil),
+		(*schema.Candle)(nil),
+		(*schema.FeaturedAsset)(nil),
+		(*schema.AssetHolderSnapshot)(nil),
+		(*schema.Cursor)(nil),
+		(*schema.AtomicSwap)(nil),
+		(*schema.AtomicSwapDeposit)(nil),
+		(*schema.ValidatorSnapshot)(nil),
+		(*schema.Label)(nil),
+		(*schema.Address)(nil),
+		(*schema.NetworkStat)(nil),
+		(*schema.AccountBalance)(nil),
+		(*schema.AccountBalanceHistory)(nil),
+		(*schema.APIKey)(nil),
+		(*schema.SupplyEvent)(nil),
+		(*schema.TimeLock)(nil),
+	} {
+		err := db.CreateTable(model, &orm.CreateTableOptions{
+			IfNotExists: true,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package db
+
+import (
+	"strings"
+
+	"mintscan/tracing"
+
+	"github.com/go-pg/pg"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// spanKey is the key of query span in data of query events
+type spanKey struct{}
+
+// tracingHook starts a span for every query. Queries made through a database handle
+// returned by WithContext are traced as children of the span in that context
+type tracingHook struct{}
+
+// BeforeQuery implements pg.QueryHook interface
+func (tracingHook) BeforeQuery(ev *pg.QueryEvent) {
+	// go-pg passes no context to queries that are not made with an explicit one,
+	// in which case the context of the database handle is used
+	ctx := ev.Ctx
+	if ctx == nil {
+		ctx = ev.DB.Context()
+	}
+
+	_, span := tracing.Tracer().Start(ctx, "db query",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("db.system", "postgresql")),
+	)
+
+	ev.Data[spanKey{}] = span
+}
+
+// AfterQuery implements pg.QueryHook interface
+func (tracingHook) AfterQuery(ev *pg.QueryEvent) {
+	span, ok := ev.Data[spanKey{}].(trace.Span)
+	if !ok {
+		return
+	}
+	defer span.End()
+
+	if query, err := ev.UnformattedQuery(); err == nil {
+		if fields := strings.Fields(query); len(fields) > 0 {
+			span.SetName("db " + strings.ToUpper(fields[0]))
+		}
+		span.SetAttributes(attribute.String("db.statement", query))
+	}
+
+	if ev.Error != nil && ev.Error != pg.ErrNoRows {
+		span.RecordError(ev.Error)
+		span.SetStatus(codes.Error, ev.Error.Error())
+	}
+}
@@ -0,0 +1,415 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"mintscan/models"
+	"mintscan/schema"
+
+	"github.com/go-pg/pg"
+	"github.com/go-pg/pg/orm"
+)
+
+// InsertTrades saves trades in database. Trades that are already saved are ignored
+func (db *Database) InsertTrades(trades []schema.Trade) error {
+	if len(trades) <= 0 {
+		return nil
+	}
+
+	_, err := db.Model(&trades).
+		OnConflict("(trade_id) DO NOTHING").
+		Insert()
+
+	if err != nil {
+		return fmt.Errorf("failed to insert trades: %s", err)
+	}
+
+	return nil
+}
+
+// UpsertCandlesFromTrades rebuilds candles in an interval that contain any trade whose id is in (after, until].
+// Each candle is aggregated from every trade in it, ordered by time, so that open and close are correct
+// regardless of the order in which trades are saved
+func (db *Database) UpsertCandlesFromTrades(interval string, duration time.Duration, after int64, until int64) error {
+	bucket := "to_timestamp(floor(extract(epoch from timestamp) / ?1) * ?1)"
+
+	_, err := db.Exec(`INSERT INTO candle (symbol, "interval", open_time, open, high, low, close, volume, quote_volume, num_trades)
+		SELECT t.symbol, ?0, c.open_time,
+			(array_agg(t.price ORDER BY t.timestamp ASC, t.id ASC))[1], max(t.price), min(t.price),
+			(array_agg(t.price ORDER BY t.timestamp DESC, t.id DESC))[1],
+			sum(t.quantity), sum(t.price * t.quantity), count(*)
+		FROM (SELECT DISTINCT symbol, `+bucket+` AS open_time FROM trade WHERE id > ?2 AND id <= ?3) AS c
+		JOIN trade AS t ON t.symbol = c.symbol
+			AND t.timestamp >= c.open_time AND t.timestamp < c.open_time + ?1 * interval '1 second'
+		GROUP BY t.symbol, c.open_time
+		ON CONFLICT (symbol, "interval", open_time) DO UPDATE SET
+			open = EXCLUDED.open, high = EXCLUDED.high, low = EXCLUDED.low, close = EXCLUDED.close,
+			volume = EXCLUDED.volume, quote_volume = EXCLUDED.quote_volume, num_trades = EXCLUDED.num_trades`,
+		interval, int64(duration.Seconds()), after, until)
+
+	if err != nil {
+		return fmt.Errorf("failed to upsert candles: %s", err)
+	}
+
+	return nil
+}
+
+// ReplaceFeaturedAssets replaces featured assets with the given asset names in display order
+func (db *Database) ReplaceFeaturedAssets(names []string) error {
+	err := db.RunInTransaction(func(tx *pg.Tx) error {
+		_, err := tx.Model((*schema.FeaturedAsset)(nil)).
+			Where("TRUE").
+			Delete()
+		if err != nil {
+			return err
+		}
+
+		if len(names) <= 0 {
+			return nil
+		}
+
+		assets := make([]schema.FeaturedAsset, 0)
+		for i, name := range names {
+			assets = append(assets, schema.FeaturedAsset{
+				Asset:    name,
+				Position: i,
+			})
+		}
+
+		_, err = tx.Model(&assets).Insert()
+		return err
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to replace featured assets: %s", err)
+	}
+
+	return nil
+}
+
+// InsertAssetHolderSnapshot saves a snapshot of asset holder distribution
+func (db *Database) InsertAssetHolderSnapshot(snapshot schema.AssetHolderSnapshot) error {
+	_, err := db.Model(&snapshot).Insert()
+	if err != nil {
+		return fmt.Errorf("failed to insert asset holder snapshot: %s", err)
+	}
+
+	return nil
+}
+
+// UpdateCursor saves position up to which an exporter job has processed
+func (db *Database) UpdateCursor(name string, position int64) error {
+	err := updateCursor(db.DB, name, position)
+	if err != nil {
+		return fmt.Errorf("failed to update cursor: %s", err)
+	}
+
+	return nil
+}
+
+// updateCursor saves position of a cursor with either a database handle or a transaction
+func updateCursor(db orm.DB, name string, position int64) error {
+	cursor := schema.Cursor{
+		Name:      name,
+		Position:  position,
+		Timestamp: time.Now().UTC(),
+	}
+
+	_, err := db.Model(&cursor).
+		OnConflict("(name) DO UPDATE").
+		Set("position = EXCLUDED.position").
+		Set("timestamp = EXCLUDED.timestamp").
+		Insert()
+
+	return err
+}
+
+// SaveSupplyEvents saves supply events and time lock changes of a transaction along with position of a cursor
+// in a single database transaction, so that running totals are not counted twice when saving is retried.
+// Unlocked time locks are deleted before the remaining ones are upserted
+func (db *Database) SaveSupplyEvents(events []schema.SupplyEvent, locks []schema.TimeLock, unlocked []schema.TimeLock, cursor string, position int64) error {
+	err := db.RunInTransaction(func(tx *pg.Tx) error {
+		if len(events) > 0 {
+			_, err := tx.Model(&events).Insert()
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, lock := range unlocked {
+			_, err := tx.Model((*schema.TimeLock)(nil)).
+				Where("address = ?", lock.Address).
+				Where("lock_id = ?", lock.LockID).
+				Delete()
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(locks) > 0 {
+			_, err := tx.Model(&locks).
+				OnConflict("(address, lock_id) DO UPDATE").
+				Set("amount = EXCLUDED.amount").
+				Insert()
+			if err != nil {
+				return err
+			}
+		}
+
+		return updateCursor(tx, cursor, position)
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to save supply events: %s", err)
+	}
+
+	return nil
+}
+
+// InsertAtomicSwap saves an atomic swap. Swaps that are already saved are ignored
+func (db *Database) InsertAtomicSwap(swap schema.AtomicSwap) error {
+	_, err := db.Model(&swap).
+		OnConflict("(swap_id) DO NOTHING").
+		Insert()
+
+	if err != nil {
+		return fmt.Errorf("failed to insert atomic swap: %s", err)
+	}
+
+	return nil
+}
+
+// InsertAtomicSwapDeposit saves a deposit into an atomic swap. Deposits that are already saved are ignored
+func (db *Database) InsertAtomicSwapDeposit(deposit schema.AtomicSwapDeposit) error {
+	_, err := db.Model(&deposit).
+		OnConflict("(swap_id, tx_hash) DO NOTHING").
+		Insert()
+
+	if err != nil {
+		return fmt.Errorf("failed to insert atomic swap deposit: %s", err)
+	}
+
+	return nil
+}
+
+// UpdateAtomicSwapClaim marks an atomic swap as claimed with the revealed random number
+func (db *Database) UpdateAtomicSwapClaim(swapID string, randomNumber string, txHash string, height int64) error {
+	_, err := db.Model((*schema.AtomicSwap)(nil)).
+		Set("status = ?", models.SwapStatusClaimed).
+		Set("random_number = ?", randomNumber).
+		Set("claim_tx_hash = ?", txHash).
+		Set("claim_height = ?", height).
+		Where("swap_id = ?", swapID).
+		Update()
+
+	if err != nil {
+		return fmt.Errorf("failed to update atomic swap claim: %s", err)
+	}
+
+	return nil
+}
+
+// UpdateAtomicSwapRefund marks an atomic swap as refunded
+func (db *Database) UpdateAtomicSwapRefund(swapID string, txHash string, height int64) error {
+	_, err := db.Model((*schema.AtomicSwap)(nil)).
+		Set("status = ?", models.SwapStatusRefunded).
+		Set("refund_tx_hash = ?", txHash).
+		Set("refund_height = ?", height).
+		Where("swap_id = ?", swapID).
+		Update()
+
+	if err != nil {
+		return fmt.Errorf("failed to update atomic swap refund: %s", err)
+	}
+
+	return nil
+}
+
+// InsertValidatorSnapshots saves validator snapshots
+func (db *Database) InsertValidatorSnapshots(snapshots []schema.ValidatorSnapshot) error {
+	if len(snapshots) <= 0 {
+		return nil
+	}
+
+	_, err := db.Model(&snapshots).Insert()
+	if err != nil {
+		return fmt.Errorf("failed to insert validator snapshots: %s", err)
+	}
+
+	return nil
+}
+
+// UpsertLabels inserts address labels, overwriting labels of addresses that are already labeled
+func (db *Database) UpsertLabels(labels []schema.Label) error {
+	if len(labels) <= 0 {
+		return nil
+	}
+
+	_, err := db.Model(&labels).
+		OnConflict("(address) DO UPDATE").
+		Set("label = EXCLUDED.label").
+		Set("category = EXCLUDED.category").
+		Set("timestamp = now()").
+		Insert()
+
+	if err != nil {
+		return fmt.Errorf("failed to upsert labels: %s", err)
+	}
+
+	return nil
+}
+
+// InsertAddresses saves signer and recipient addresses of transactions whose id is in (afterID, toID] with the height and time
+// they were first seen. Addresses that are already saved are kept as they are
+func (db *Database) InsertAddresses(afterID int32, toID int32) error {
+	_, err := db.Exec(`INSERT INTO address (address, height, first_seen)
+		SELECT address, min(height), min(timestamp)
+		FROM (`+txAddressesQuery("t.id > ?0 AND t.id <= ?1")+`) a
+		WHERE address IS NOT NULL
+		GROUP BY 1
+		ON CONFLICT (address) DO NOTHING`, afterID, toID)
+
+	if err != nil {
+		return fmt.Errorf("failed to insert addresses: %s", err)
+	}
+
+	return nil
+}
+
+// UpsertNetworkStats saves network statistics in database. Statistics in the same interval are overwritten
+func (db *Database) UpsertNetworkStats(stats []schema.NetworkStat) error {
+	if len(stats) <= 0 {
+		return nil
+	}
+
+	_, err := db.Model(&stats).
+		OnConflict(`("interval", time) DO UPDATE`).
+		Set("num_blocks = EXCLUDED.num_blocks").
+		Set("num_txs = EXCLUDED.num_txs").
+		Set("active_addresses = EXCLUDED.active_addresses").
+		Set("new_addresses = EXCLUDED.new_addresses").
+		Set("msg_types = EXCLUDED.msg_types").
+		Set("est_avg_fee = EXCLUDED.est_avg_fee").
+		Set("avg_block_time = EXCLUDED.avg_block_time").
+		Set("total_gas = EXCLUDED.total_gas").
+		Insert()
+
+	if err != nil {
+		return fmt.Errorf("failed to upsert network stats: %s", err)
+	}
+
+	return nil
+}
+
+// ReplaceAccountBalances replaces balances of an account with the given balances. Balances of assets
+// that are no longer held are deleted and changes of total balances are saved in balance history
+func (db *Database) ReplaceAccountBalances(address string, balances []schema.AccountBalance) error {
+	err := db.RunInTransaction(func(tx *pg.Tx) error {
+		prev := make([]schema.AccountBalance, 0)
+		err := tx.Model(&prev).
+			Where("address = ?", address).
+			Select()
+		if err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+		prevTotals := make(map[string]float64)
+		for _, balance := range prev {
+			prevTotals[balance.Asset] = balance.Total
+		}
+
+		history := make([]schema.AccountBalanceHistory, 0)
+		held := make([]string, 0)
+
+		for i := range balances {
+			balances[i].Timestamp = now
+			held = append(held, balances[i].Asset)
+
+			total, ok := prevTotals[balances[i].Asset]
+			if !ok || total != balances[i].Total {
+				history = append(history, schema.AccountBalanceHistory{
+					Address:   address,
+					Asset:     balances[i].Asset,
+					Total:     balances[i].Total,
+					Timestamp: now,
+				})
+			}
+			delete(prevTotals, balances[i].Asset)
+		}
+
+		for asset := range prevTotals {
+			history = append(history, schema.AccountBalanceHistory{
+				Address:   address,
+				Asset:     asset,
+				Total:     0,
+				Timestamp: now,
+			})
+		}
+
+		query := tx.Model((*schema.AccountBalance)(nil)).
+			Where("address = ?", address)
+		if len(held) > 0 {
+			query = query.WhereIn("asset NOT IN (?)", held)
+		}
+
+		_, err = query.Delete()
+		if err != nil {
+			return err
+		}
+
+		if len(balances) > 0 {
+			_, err = tx.Model(&balances).
+				OnConflict("(address, asset) DO UPDATE").
+				Set("free = EXCLUDED.free").
+				Set("locked = EXCLUDED.locked").
+				Set("frozen = EXCLUDED.frozen").
+				Set("total = EXCLUDED.total").
+				Set("timestamp = EXCLUDED.timestamp").
+				Insert()
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(history) > 0 {
+			_, err = tx.Model(&history).Insert()
+		}
+
+		return err
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to replace account balances: %s", err)
+	}
+
+	return nil
+}
+
+// InsertAPIKey saves an API key and sets its id
+func (db *Database) InsertAPIKey(key *schema.APIKey) error {
+	_, err := db.Model(key).
+		Returning("id").
+		Insert()
+
+	if err != nil {
+		return fmt.Errorf("failed to insert api key: %s", err)
+	}
+
+	return nil
+}
+
+// DisableAPIKey disables an API key by id. It returns false if the key doesn't exist
+func (db *Database) DisableAPIKey(id int32) (bool, error) {
+	res, err := db.Model((*schema.APIKey)(nil)).
+		Set("disabled = ?", true).
+		Where("id = ?", id).
+		Update()
+
+	if err != nil {
+		return false, fmt.Errorf("failed to disable api key: %s", err)
+	}
+
+	return res.RowsAffected() > 0, nil
+}
@@ -2,13 +2,18 @@ package db
 
 import (
 	"fmt"
+	"time"
 
 	"mintscan/models"
 	"mintscan/schema"
 
 	"github.com/go-pg/pg"
+	"github.com/go-pg/pg/orm"
 )
 
+// txsBatchSize is the number of transactions queried at once when paging through every matching transaction
+const txsBatchSize = 1000
+
 // QueryBlocks queries blocks with pagination params, such as limit, before, after, and offset
 func (db *Database) QueryBlocks(before int, after int, limit int) ([]schema.Block, error) {
 	blocks := make([]schema.Block, 0)
@@ -182,6 +187,44 @@ func (db *Database) QueryTxByHash(hash string) (schema.Transaction, error) {
 	return tx, nil
 }
 
+// QueryBlockByHeight queries block by block height
+func (db *Database) QueryBlockByHeight(height int64) (schema.Block, error) {
+	var block schema.Block
+	err := db.Model(&block).
+		Where("height = ?", height).
+		Limit(1).
+		Select()
+
+	if err == pg.ErrNoRows {
+		return block, fmt.Errorf("no rows in block table: %s", err)
+	}
+
+	if err != nil {
+		return block, fmt.Errorf("unexpected database error: %s", err)
+	}
+
+	return block, nil
+}
+
+// QueryBlockByHash queries block by block hash
+func (db *Database) QueryBlockByHash(hash string) (schema.Block, error) {
+	var block schema.Block
+	err := db.Model(&block).
+		Where("block_hash = ?", hash).
+		Limit(1).
+		Select()
+
+	if err == pg.ErrNoRows {
+		return block, fmt.Errorf("no rows in block table: %s", err)
+	}
+
+	if err != nil {
+		return block, fmt.Errorf("unexpected database error: %s", err)
+	}
+
+	return block, nil
+}
+
 // QueryTxsByType queries transactions with tx type and start and end time
 func (db *Database) QueryTxsByType(txType string, startTime int64, endTime int64, before int, after int, limit int) ([]schema.Transaction, error) {
 	txs := make([]schema.Transaction, 0)
@@ -220,6 +263,78 @@ func (db *Database) QueryTxsByType(txType string, startTime int64, endTime int64
 	return txs, nil
 }
 
+// QueryTxsByMsgFilters queries successful transactions whose messages contain any of the given filters.
+// Each filter is a JSON array matched against messages column with jsonb containment operator
+func (db *Database) QueryTxsByMsgFilters(filters []string, before int, limit int) ([]schema.Transaction, error) {
+	txs := make([]schema.Transaction, 0)
+
+	q := db.Model(&txs).
+		Where("code = 0").
+		WhereGroup(func(q *orm.Query) (*orm.Query, error) {
+			for _, filter := range filters {
+				q = q.WhereOr("messages @> ?::jsonb", filter)
+			}
+			return q, nil
+		})
+
+	if before > 0 {
+		q = q.Where("id < ?", before)
+	}
+
+	err := q.Limit(limit).
+		Order("id DESC").
+		Select()
+
+	if err == pg.ErrNoRows {
+		return txs, fmt.Errorf("no rows in transaction table: %s", err)
+	}
+
+	if err != nil {
+		return txs, fmt.Errorf("unexpected database error: %s", err)
+	}
+
+	return txs, nil
+}
+
+// CountMsgsByMsgFilter counts messages of successful transactions that match the message filter.
+// Unlike counting transactions, every matching message of a multi-message transaction is counted
+func (db *Database) CountMsgsByMsgFilter(filter string) (int, error) {
+	var count int
+
+	_, err := db.QueryOne(pg.Scan(&count), `SELECT count(*) FROM transaction AS t, jsonb_array_elements(t.messages) AS m
+		WHERE t.code = 0 AND t.messages @> ?0::jsonb AND m @> (?0::jsonb)->0`, filter)
+	if err != nil {
+		return 0, fmt.Errorf("unexpected database error: %s", err)
+	}
+
+	return count, nil
+}
+
+// QueryTxsByMsgFiltersUntil queries successful transactions whose messages contain any of the given filters
+// and that are made at or before the given time in descending order
+func (db *Database) QueryTxsByMsgFiltersUntil(filters []string, until time.Time, limit int) ([]schema.Transaction, error) {
+	txs := make([]schema.Transaction, 0)
+
+	err := db.Model(&txs).
+		Where("code = 0").
+		Where("timestamp <= ?", until).
+		WhereGroup(func(q *orm.Query) (*orm.Query, error) {
+			for _, filter := range filters {
+				q = q.WhereOr("messages @> ?::jsonb", filter)
+			}
+			return q, nil
+		}).
+		Limit(limit).
+		Order("id DESC").
+		Select()
+
+	if err != nil {
+		return txs, fmt.Errorf("unexpected database error: %s", err)
+	}
+
+	return txs, nil
+}
+
 // ExistToken checks to see if a token exists
 func (db *Database) ExistToken(originalSymbol string) (bool, error) {
 	var token models.Token
@@ -254,14 +369,14 @@ func (db *Database) CountTotalTxsNum() (int32, error) {
 	return tx.ID, nil
 }
 
-// QueryAssetChartHistory queries asset chart history
+// QueryAssetChartHistory queries hourly asset chart history since the given time
 // Stats Exporter needs to be executed and run at least 24 hours to get the result
-func (db *Database) QueryAssetChartHistory(asset string, limit int) ([]schema.StatAssetInfoList1H, error) {
+func (db *Database) QueryAssetChartHistory(asset string, since time.Time) ([]schema.StatAssetInfoList1H, error) {
 	chartHistory := make([]schema.StatAssetInfoList1H, 0)
 
 	err := db.Model(&chartHistory).
 		Where("asset = ?", asset).
-		Limit(limit).
+		Where("timestamp >= ?", since).
 		Order("id DESC").
 		Select()
 
@@ -276,6 +391,47 @@ func (db *Database) QueryAssetChartHistory(asset string, limit int) ([]schema.St
 	return chartHistory, nil
 }
 
+// QueryAssetChartHistory24H queries daily asset chart history since the given time
+func (db *Database) QueryAssetChartHistory24H(asset string, since time.Time) ([]schema.StatAssetInfoList24H, error) {
+	chartHistory := make([]schema.StatAssetInfoList24H, 0)
+
+	err := db.Model(&chartHistory).
+		Where("asset = ?", asset).
+		Where("timestamp >= ?", since).
+		Order("id DESC").
+		Select()
+
+	if err == pg.ErrNoRows {
+		return chartHistory, fmt.Errorf("no rows in stat_asset_info_list24_h table: %s", err)
+	}
+
+	if err != nil {
+		return chartHistory, fmt.Errorf("unexpected database error: %s", err)
+	}
+
+	return chartHistory, nil
+}
+
+// QueryFeaturedAssets queries featured asset names in display order
+func (db *Database) QueryFeaturedAssets() ([]string, error) {
+	assets := make([]schema.FeaturedAsset, 0)
+
+	err := db.Model(&assets).
+		Order("position ASC").
+		Select()
+
+	if err != nil {
+		return []string{}, fmt.Errorf("unexpected database error: %s", err)
+	}
+
+	names := make([]string, 0)
+	for _, asset := range assets {
+		names = append(names, asset.Asset)
+	}
+
+	return names, nil
+}
+
 // QueryValidators queries validators in a validator set saved in database
 func (db *Database) QueryValidators() ([]*schema.Validator, error) {
 	vals := make([]*schema.Validator, 0)
@@ -374,3 +530,762 @@ func (db *Database) QueryValidatorByMoniker(address string) (schema.Validator, e
 
 	return val, nil
 }
+
+// QueryLatestTradeTime queries time of the latest trade saved in database
+func (db *Database) QueryLatestTradeTime() (time.Time, error) {
+	var trade schema.Trade
+
+	err := db.Model(&trade).
+		Column("timestamp").
+		Limit(1).
+		Order("timestamp DESC").
+		Select()
+
+	if err == pg.ErrNoRows {
+		return time.Time{}, fmt.Errorf("no rows in trade table: %s", err)
+	}
+
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unexpected database error: %s", err)
+	}
+
+	return trade.Timestamp, nil
+}
+
+// QueryLatestTradeID queries id of the latest trade saved in database. It returns 0 if no trade is saved
+func (db *Database) QueryLatestTradeID() (int64, error) {
+	var trade schema.Trade
+
+	err := db.Model(&trade).
+		Column("id").
+		Limit(1).
+		Order("id DESC").
+		Select()
+
+	if err == pg.ErrNoRows {
+		return 0, nil
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("unexpected database error: %s", err)
+	}
+
+	return int64(trade.ID), nil
+}
+
+// QueryCandles queries candles of a symbol in an interval between from and to
+func (db *Database) QueryCandles(symbol string, interval string, from time.Time, to time.Time) ([]schema.Candle, error) {
+	candles := make([]schema.Candle, 0)
+
+	err := db.Model(&candles).
+		Where(`symbol = ? AND "interval" = ?`, symbol, interval).
+		Where("open_time BETWEEN ? AND ?", from, to).
+		Order("open_time ASC").
+		Select()
+
+	if err == pg.ErrNoRows {
+		return candles, fmt.Errorf("no rows in candle table: %s", err)
+	}
+
+	if err != nil {
+		return candles, fmt.Errorf("unexpected database error: %s", err)
+	}
+
+	return candles, nil
+}
+
+// QueryCandleBefore queries the latest candle of a symbol in an interval opened before the given time.
+// It returns nil if there is no such candle
+func (db *Database) QueryCandleBefore(symbol string, interval string, before time.Time) (*schema.Candle, error) {
+	var candle schema.Candle
+
+	err := db.Model(&candle).
+		Where(`symbol = ? AND "interval" = ?`, symbol, interval).
+		Where("open_time < ?", before).
+		Limit(1).
+		Order("open_time DESC").
+		Select()
+
+	if err == pg.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("unexpected database error: %s", err)
+	}
+
+	return &candle, nil
+}
+
+// QueryLatestAssetHolderSnapshot queries the latest snapshot of asset holder distribution
+func (db *Database) QueryLatestAssetHolderSnapshot(asset string) (schema.AssetHolderSnapshot, error) {
+	var snapshot schema.AssetHolderSnapshot
+
+	err := db.Model(&snapshot).
+		Where("asset = ?", asset).
+		Limit(1).
+		Order("timestamp DESC").
+		Select()
+
+	if err == pg.ErrNoRows {
+		return snapshot, fmt.Errorf("no rows in asset_holder_snapshot table: %s", err)
+	}
+
+	if err != nil {
+		return snapshot, fmt.Errorf("unexpected database error: %s", err)
+	}
+
+	return snapshot, nil
+}
+
+// QueryAssetHolderSnapshots queries snapshots of asset holder distribution since the given time in chronological order
+func (db *Database) QueryAssetHolderSnapshots(asset string, since time.Time) ([]schema.AssetHolderSnapshot, error) {
+	snapshots := make([]schema.AssetHolderSnapshot, 0)
+
+	err := db.Model(&snapshots).
+		Where("asset = ?", asset).
+		Where("timestamp >= ?", since).
+		Order("timestamp ASC").
+		Select()
+
+	if err != nil {
+		return snapshots, fmt.Errorf("unexpected database error: %s", err)
+	}
+
+	return snapshots, nil
+}
+
+// QueryCursor queries position up to which an exporter job has processed. It returns 0 if the job has never run
+func (db *Database) QueryCursor(name string) (int64, error) {
+	var cursor schema.Cursor
+
+	err := db.Model(&cursor).
+		Where("name = ?", name).
+		Limit(1).
+		Select()
+
+	if err == pg.ErrNoRows {
+		return 0, nil
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("unexpected database error: %s", err)
+	}
+
+	return cursor.Position, nil
+}
+
+// QueryTxsByMsgFiltersAfter queries successful transactions whose messages contain any of the given filters
+// and whose id is greater than after in ascending order
+func (db *Database) QueryTxsByMsgFiltersAfter(filters []string, after int64, limit int) ([]schema.Transaction, error) {
+	txs := make([]schema.Transaction, 0)
+
+	err := db.Model(&txs).
+		Where("code = 0").
+		Where("id > ?", after).
+		WhereGroup(func(q *orm.Query) (*orm.Query, error) {
+			for _, filter := range filters {
+				q = q.WhereOr("messages @> ?::jsonb", filter)
+			}
+			return q, nil
+		}).
+		Limit(limit).
+		Order("id ASC").
+		Select()
+
+	if err == pg.ErrNoRows {
+		return txs, fmt.Errorf("no rows in transaction table: %s", err)
+	}
+
+	if err != nil {
+		return txs, fmt.Errorf("unexpected database error: %s", err)
+	}
+
+	return txs, nil
+}
+
+// QueryAllTxsByMsgFilters queries every successful transaction whose messages contain any of the given filters
+// in ascending order, paging through them in batches of txsBatchSize
+func (db *Database) QueryAllTxsByMsgFilters(filters []string) ([]schema.Transaction, error) {
+	result := make([]schema.Transaction, 0)
+	after := int64(0)
+
+	for {
+		txs, err := db.QueryTxsByMsgFiltersAfter(filters, after, txsBatchSize)
+		if err != nil {
+			return result, err
+		}
+
+		result = append(result, txs...)
+
+		if len(txs) < txsBatchSize {
+			return result, nil
+		}
+
+		after = int64(txs[len(txs)-1].ID)
+	}
+}
+
+// QueryAtomicSwaps queries atomic swaps with pagination params. Swaps are filtered by
+// sender or recipient when address is not empty
+func (db *Database) QueryAtomicSwaps(address string, before int, limit int) ([]schema.AtomicSwap, error) {
+	swaps := make([]schema.AtomicSwap, 0)
+
+	q := db.Model(&swaps)
+
+	if address != "" {
+		q = q.WhereGroup(func(q *orm.Query) (*orm.Query, error) {
+			return q.Where(`"from" = ?`, address).WhereOr(`"to" = ?`, address), nil
+		})
+	}
+
+	if before > 0 {
+		q = q.Where("id < ?", before)
+	}
+
+	err := q.Limit(limit).
+		Order("id DESC").
+		Select()
+
+	if err != nil {
+		return swaps, fmt.Errorf("unexpected database error: %s", err)
+	}
+
+	return swaps, nil
+}
+
+// QueryAtomicSwap queries an atomic swap by its swap id. It returns nil if the swap doesn't exist
+func (db *Database) QueryAtomicSwap(swapID string) (*schema.AtomicSwap, error) {
+	var swap schema.AtomicSwap
+
+	err := db.Model(&swap).
+		Where("swap_id = ?", swapID).
+		Limit(1).
+		Select()
+
+	if err == pg.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("unexpected database error: %s", err)
+	}
+
+	return &swap, nil
+}
+
+// QueryAtomicSwapDeposits queries deposits into the given atomic swaps
+func (db *Database) QueryAtomicSwapDeposits(swapIDs []string) ([]schema.AtomicSwapDeposit, error) {
+	deposits := make([]schema.AtomicSwapDeposit, 0)
+
+	if len(swapIDs) <= 0 {
+		return deposits, nil
+	}
+
+	err := db.Model(&deposits).
+		WhereIn("swap_id IN (?)", swapIDs).
+		Order("id ASC").
+		Select()
+
+	if err != nil {
+		return deposits, fmt.Errorf("unexpected database error: %s", err)
+	}
+
+	return deposits, nil
+}
+
+// QueryLatestValidatorSnapshots queries the latest snapshot of every validator
+func (db *Database) QueryLatestValidatorSnapshots() ([]schema.ValidatorSnapshot, error) {
+	snapshots := make([]schema.ValidatorSnapshot, 0)
+
+	_, err := db.Query(&snapshots, `SELECT DISTINCT ON (operator_address) * FROM validator_snapshot
+		ORDER BY operator_address, id DESC`)
+
+	if err != nil {
+		return snapshots, fmt.Errorf("unexpected database error: %s", err)
+	}
+
+	return snapshots, nil
+}
+
+// QueryValidatorSnapshots queries snapshots of a validator in chronological order
+func (db *Database) QueryValidatorSnapshots(operatorAddress string) ([]schema.ValidatorSnapshot, error) {
+	snapshots := make([]schema.ValidatorSnapshot, 0)
+
+	err := db.Model(&snapshots).
+		Where("operator_address = ?", operatorAddress).
+		Order("id ASC").
+		Select()
+
+	if err != nil {
+		return snapshots, fmt.Errorf("unexpected database error: %s", err)
+	}
+
+	return snapshots, nil
+}
+
+// QueryLabels queries labels of the given addresses
+func (db *Database) QueryLabels(addresses []string) ([]schema.Label, error) {
+	labels := make([]schema.Label, 0)
+
+	if len(addresses) <= 0 {
+		return labels, nil
+	}
+
+	err := db.Model(&labels).
+		WhereIn("address IN (?)", addresses).
+		Select()
+
+	if err != nil {
+		return labels, fmt.Errorf("unexpected database error: %s", err)
+	}
+
+	return labels, nil
+}
+
+// QueryAllLabels queries every address label
+func (db *Database) QueryAllLabels() ([]schema.Label, error) {
+	labels := make([]schema.Label, 0)
+
+	err := db.Model(&labels).
+		Order("address ASC").
+		Select()
+
+	if err != nil {
+		return labels, fmt.Errorf("unexpected database error: %s", err)
+	}
+
+	return labels, nil
+}
+
+// QueryPreCommits queries precommits of validators that signed the block at the given height
+func (db *Database) QueryPreCommits(height int64) ([]schema.PreCommit, error) {
+	precommits := make([]schema.PreCommit, 0)
+
+	err := db.Model(&precommits).
+		Where("height = ?", height).
+		Select()
+
+	if err != nil {
+		return precommits, fmt.Errorf("unexpected database error: %s", err)
+	}
+
+	return precommits, nil
+}
+
+// QueryTxIDsAfter queries ids and times of transactions whose id is greater than the given id
+func (db *Database) QueryTxIDsAfter(afterID int32, limit int) ([]schema.Transaction, error) {
+	txs := make([]schema.Transaction, 0)
+
+	err := db.Model(&txs).
+		Column("id", "timestamp").
+		Where("id > ?", afterID).
+		Order("id ASC").
+		Limit(limit).
+		Select()
+
+	if err != nil {
+		return txs, fmt.Errorf("unexpected database error: %s", err)
+	}
+
+	return txs, nil
+}
+
+// QueryEarliestBlockTime queries time of the earliest block saved in database
+func (db *Database) QueryEarliestBlockTime() (time.Time, error) {
+	var block schema.Block
+
+	err := db.Model(&block).
+		Order("id ASC").
+		Limit(1).
+		Select()
+
+	if err == pg.ErrNoRows {
+		return time.Time{}, fmt.Errorf("no rows in block table: %s", err)
+	}
+
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unexpected database error: %s", err)
+	}
+
+	return block.Timestamp, nil
+}
+
+// QueryLatestNetworkStatTime queries start time of the latest network statistics in the given interval
+func (db *Database) QueryLatestNetworkStatTime(interval string) (time.Time, error) {
+	var stat schema.NetworkStat
+
+	err := db.Model(&stat).
+		Where(`"interval" = ?`, interval).
+		Order("time DESC").
+		Limit(1).
+		Select()
+
+	if err == pg.ErrNoRows {
+		return time.Time{}, fmt.Errorf("no rows in network stat table: %s", err)
+	}
+
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unexpected database error: %s", err)
+	}
+
+	return stat.Time, nil
+}
+
+// QueryNetworkStats queries network statistics in the given interval between from and to
+func (db *Database) QueryNetworkStats(interval string, from time.Time, to time.Time) ([]schema.NetworkStat, error) {
+	stats := make([]schema.NetworkStat, 0)
+
+	err := db.Model(&stats).
+		Where(`"interval" = ?`, interval).
+		Where("time >= ?", from).
+		Where("time <= ?", to).
+		Order("time ASC").
+		Select()
+
+	if err != nil {
+		return stats, fmt.Errorf("unexpected database error: %s", err)
+	}
+
+	return stats, nil
+}
+
+// txAddressesQuery returns a query that selects signers and recipients of transactions matching the
+// condition on transaction t, with the height and time of each transaction. Recipients are outputs of
+// transfers and recipients of atomic swaps
+func txAddressesQuery(cond string) string {
+	return `SELECT t.height, t.timestamp, s->>'address' AS address
+		FROM transaction t, jsonb_array_elements(t.signatures) s
+		WHERE ` + cond + `
+		UNION ALL
+		SELECT t.height, t.timestamp, o->>'address' AS address
+		FROM transaction t, jsonb_array_elements(t.messages) m, jsonb_array_elements(m->'value'->'outputs') o
+		WHERE m->>'type' = 'cosmos-sdk/Send' AND ` + cond + `
+		UNION ALL
+		SELECT t.height, t.timestamp, m->'value'->>'to' AS address
+		FROM transaction t, jsonb_array_elements(t.messages) m
+		WHERE m->>'type' = 'tokens/HTLTMsg' AND ` + cond
+}
+
+// AggregateNetworkStats aggregates blocks, transactions and addresses between from and to into
+// network statistics in buckets of the given duration. Every bucket between from and to is returned,
+// including buckets without any block. Average fees are not aggregated here since they are estimated
+// from message types and fee parameters
+func (db *Database) AggregateNetworkStats(interval string, duration time.Duration, from time.Time, to time.Time) ([]schema.NetworkStat, error) {
+	seconds := int64(duration / time.Second)
+	bucket := func(column string) string {
+		return fmt.Sprintf("to_timestamp(floor(extract(epoch from %s) / ?0) * ?0)", column)
+	}
+
+	stats := make(map[time.Time]*schema.NetworkStat)
+	stat := func(t time.Time) *schema.NetworkStat {
+		t = t.UTC()
+		if _, ok := stats[t]; !ok {
+			stats[t] = &schema.NetworkStat{Interval: interval, Time: t, MsgTypes: "{}"}
+		}
+		return stats[t]
+	}
+
+	for t := from; t.Before(to); t = t.Add(duration) {
+		stat(t)
+	}
+
+	var blocks []struct {
+		Time      time.Time
+		NumBlocks int64
+		MinTime   time.Time
+		MaxTime   time.Time
+	}
+	_, err := db.Query(&blocks, `SELECT `+bucket("timestamp")+` AS time, count(*) AS num_blocks,
+		min(timestamp) AS min_time, max(timestamp) AS max_time
+		FROM block WHERE timestamp >= ?1 AND timestamp < ?2 GROUP BY 1`, seconds, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("unexpected database error: %s", err)
+	}
+
+	for _, b := range blocks {
+		s := stat(b.Time)
+		s.NumBlocks = b.NumBlocks
+		if b.NumBlocks > 1 {
+			s.AvgBlockTime = b.MaxTime.Sub(b.MinTime).Seconds() / float64(b.NumBlocks-1)
+		}
+	}
+
+	var txs []struct {
+		Time     time.Time
+		NumTxs   int64
+		TotalGas int64
+	}
+	_, err = db.Query(&txs, `SELECT `+bucket("timestamp")+` AS time, count(*) AS num_txs,
+		coalesce(sum(gas_used), 0) AS total_gas
+		FROM transaction WHERE timestamp >= ?1 AND timestamp < ?2 GROUP BY 1`, seconds, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("unexpected database error: %s", err)
+	}
+
+	for _, t := range txs {
+		s := stat(t.Time)
+		s.NumTxs = t.NumTxs
+		s.TotalGas = t.TotalGas
+	}
+
+	var msgTypes []struct {
+		Time     time.Time
+		MsgTypes string
+	}
+	_, err = db.Query(&msgTypes, `SELECT time, jsonb_object_agg(type, num) AS msg_types FROM (
+		SELECT `+bucket("t.timestamp")+` AS time, m->>'type' AS type, count(*) AS num
+		FROM transaction t, jsonb_array_elements(t.messages) m
+		WHERE t.timestamp >= ?1 AND t.timestamp < ?2 GROUP BY 1, 2) m GROUP BY 1`, seconds, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("unexpected database error: %s", err)
+	}
+
+	for _, m := range msgTypes {
+		stat(m.Time).MsgTypes = m.MsgTypes
+	}
+
+	var active []struct {
+		Time  time.Time
+		Count int64
+	}
+	_, err = db.Query(&active, `SELECT `+bucket("timestamp")+` AS time, count(DISTINCT address) AS count
+		FROM (`+txAddressesQuery("t.timestamp >= ?1 AND t.timestamp < ?2")+`) a
+		WHERE address IS NOT NULL GROUP BY 1`, seconds, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("unexpected database error: %s", err)
+	}
+
+	for _, a := range active {
+		stat(a.Time).ActiveAddresses = a.Count
+	}
+
+	var newAddresses []struct {
+		Time  time.Time
+		Count int64
+	}
+	_, err = db.Query(&newAddresses, `SELECT `+bucket("first_seen")+` AS time, count(*) AS count
+		FROM address WHERE first_seen >= ?1 AND first_seen < ?2 GROUP BY 1`, seconds, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("unexpected database error: %s", err)
+	}
+
+	for _, n := range newAddresses {
+		stat(n.Time).NewAddresses = n.Count
+	}
+
+	result := make([]schema.NetworkStat, 0)
+	for _, s := range stats {
+		result = append(result, *s)
+	}
+
+	return result, nil
+}
+
+// QueryLatestTxID queries id of the latest transaction saved in database
+func (db *Database) QueryLatestTxID() (int32, error) {
+	var tx schema.Transaction
+
+	err := db.Model(&tx).
+		Column("id").
+		Order("id DESC").
+		Limit(1).
+		Select()
+
+	if err == pg.ErrNoRows {
+		return 0, nil
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("unexpected database error: %s", err)
+	}
+
+	return tx.ID, nil
+}
+
+// QueryTxsAfter queries transactions whose id is greater than the given id
+func (db *Database) QueryTxsAfter(afterID int32, limit int) ([]schema.Transaction, error) {
+	txs := make([]schema.Transaction, 0)
+
+	err := db.Model(&txs).
+		Where("id > ?", afterID).
+		Order("id ASC").
+		Limit(limit).
+		Select()
+
+	if err != nil {
+		return txs, fmt.Errorf("unexpected database error: %s", err)
+	}
+
+	return txs, nil
+}
+
+// QueryAddressesAfter queries addresses whose id is greater than the given id
+func (db *Database) QueryAddressesAfter(afterID int32, limit int) ([]schema.Address, error) {
+	addresses := make([]schema.Address, 0)
+
+	err := db.Model(&addresses).
+		Where("id > ?", afterID).
+		Order("id ASC").
+		Limit(limit).
+		Select()
+
+	if err != nil {
+		return addresses, fmt.Errorf("unexpected database error: %s", err)
+	}
+
+	return addresses, nil
+}
+
+// QueryStaleBalanceAddresses queries addresses of the largest balances that are updated before the given time
+func (db *Database) QueryStaleBalanceAddresses(before time.Time, limit int) ([]string, error) {
+	balances := make([]schema.AccountBalance, 0)
+
+	err := db.Model(&balances).
+		Column("address").
+		Where("timestamp < ?", before).
+		Order("total DESC").
+		Limit(limit).
+		Select()
+
+	if err != nil {
+		return []string{}, fmt.Errorf("unexpected database error: %s", err)
+	}
+
+	addresses := make([]string, 0)
+	for _, balance := range balances {
+		addresses = append(addresses, balance.Address)
+	}
+
+	return addresses, nil
+}
+
+// QueryTopAccountBalances queries the largest balances of an asset
+func (db *Database) QueryTopAccountBalances(asset string, limit int) ([]schema.AccountBalance, error) {
+	balances := make([]schema.AccountBalance, 0)
+
+	err := db.Model(&balances).
+		Where("asset = ?", asset).
+		Order("total DESC").
+		Limit(limit).
+		Select()
+
+	if err != nil {
+		return balances, fmt.Errorf("unexpected database error: %s", err)
+	}
+
+	return balances, nil
+}
+
+// QueryAccountBalancesAt queries total balances of an asset held by the given addresses at the given time.
+// Addresses whose balances are not tracked at that time are omitted
+func (db *Database) QueryAccountBalancesAt(asset string, addresses []string, at time.Time) ([]schema.AccountBalanceHistory, error) {
+	history := make([]schema.AccountBalanceHistory, 0)
+
+	if len(addresses) <= 0 {
+		return history, nil
+	}
+
+	_, err := db.Query(&history, `SELECT DISTINCT ON (address) * FROM account_balance_history
+		WHERE asset = ? AND address IN (?) AND timestamp <= ?
+		ORDER BY address, timestamp DESC, id DESC`, asset, pg.In(addresses), at)
+
+	if err != nil {
+		return history, fmt.Errorf("unexpected database error: %s", err)
+	}
+
+	return history, nil
+}
+
+// QueryAPIKey queries an API key by its hash. It returns nil if the key doesn't exist
+func (db *Database) QueryAPIKey(keyHash string) (*schema.APIKey, error) {
+	var key schema.APIKey
+
+	err := db.Model(&key).
+		Where("key_hash = ?", keyHash).
+		Select()
+
+	if err == pg.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("unexpected database error: %s", err)
+	}
+
+	return &key, nil
+}
+
+// QueryAPIKeys queries every API key
+func (db *Database) QueryAPIKeys() ([]schema.APIKey, error) {
+	keys := make([]schema.APIKey, 0)
+
+	err := db.Model(&keys).
+		Order("id ASC").
+		Select()
+
+	if err != nil {
+		return keys, fmt.Errorf("unexpected database error: %s", err)
+	}
+
+	return keys, nil
+}
+
+// QueryLatestSupplyEvent queries the latest supply event of an asset, which holds its running totals.
+// It returns an empty event if the asset has no supply event
+func (db *Database) QueryLatestSupplyEvent(asset string) (schema.SupplyEvent, error) {
+	var event schema.SupplyEvent
+
+	err := db.Model(&event).
+		Where("asset = ?", asset).
+		Order("id DESC").
+		Limit(1).
+		Select()
+
+	if err == pg.ErrNoRows {
+		return schema.SupplyEvent{Asset: asset}, nil
+	}
+
+	if err != nil {
+		return event, fmt.Errorf("unexpected database error: %s", err)
+	}
+
+	return event, nil
+}
+
+// QuerySupplyEvents queries supply events of an asset with pagination params
+func (db *Database) QuerySupplyEvents(asset string, before int, limit int) ([]schema.SupplyEvent, error) {
+	events := make([]schema.SupplyEvent, 0)
+
+	q := db.Model(&events).
+		Where("asset = ?", asset)
+
+	if before > 0 {
+		q = q.Where("id < ?", before)
+	}
+
+	err := q.Limit(limit).
+		Order("id DESC").
+		Select()
+
+	if err != nil {
+		return events, fmt.Errorf("unexpected database error: %s", err)
+	}
+
+	return events, nil
+}
+
+// QueryTimeLocks queries active time locks of an account
+func (db *Database) QueryTimeLocks(address string) ([]schema.TimeLock, error) {
+	locks := make([]schema.TimeLock, 0)
+
+	err := db.Model(&locks).
+		Where("address = ?", address).
+		Select()
+
+	if err != nil {
+		return locks, fmt.Errorf("unexpected database error: %s", err)
+	}
+
+	return locks, nil
+}
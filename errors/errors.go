@@ -25,10 +25,12 @@ const (
 	NotAllowed         ErrorCode = 204
 	FailedConversion   ErrorCode = 205
 	InvalidMessageType ErrorCode = 207
+	InvalidAPIKey      ErrorCode = 208
 
 	OverMaxLimit                      ErrorCode = 301
 	FailedUnmarshalJSON               ErrorCode = 302
 	FailedMarshalBinaryLengthPrefixed ErrorCode = 303
+	TooManyRequests                   ErrorCode = 304
 
 	RequiredParam ErrorCode = 601
 	InvalidParam  ErrorCode = 602
@@ -49,6 +51,8 @@ func ErrorCodeToErrorMsg(code ErrorCode) ErrorMsg {
 		return "NotExist"
 	case NotAllowed:
 		return "NotAllowed"
+	case InvalidAPIKey:
+		return "InvalidAPIKey"
 	case FailedConversion:
 		return "FailedConversion"
 	case OverMaxLimit:
@@ -57,6 +61,8 @@ func ErrorCodeToErrorMsg(code ErrorCode) ErrorMsg {
 		return "FailedUnmarshalJSON"
 	case FailedMarshalBinaryLengthPrefixed:
 		return "FailedMarshalBinaryLengthPrefixed"
+	case TooManyRequests:
+		return "TooManyRequests"
 	default:
 		return "Unknown"
 	}
@@ -126,6 +132,14 @@ func ErrNotAllowed(w http.ResponseWriter, statusCode int) {
 	PrintException(w, statusCode, wrapError)
 }
 
+func ErrInvalidAPIKey(w http.ResponseWriter, statusCode int) {
+	wrapError := WrapError{
+		ErrorCode: InvalidAPIKey,
+		ErrorMsg:  ErrorCodeToErrorMsg(InvalidAPIKey),
+	}
+	PrintException(w, statusCode, wrapError)
+}
+
 func ErrFailedConversion(w http.ResponseWriter, statusCode int) {
 	wrapError := WrapError{
 		ErrorCode: FailedConversion,
@@ -158,6 +172,14 @@ func ErrFailedMarshalBinaryLengthPrefixed(w http.ResponseWriter, statusCode int)
 	PrintException(w, statusCode, wrapError)
 }
 
+func ErrTooManyRequests(w http.ResponseWriter, statusCode int) {
+	wrapError := WrapError{
+		ErrorCode: TooManyRequests,
+		ErrorMsg:  ErrorCodeToErrorMsg(TooManyRequests),
+	}
+	PrintException(w, statusCode, wrapError)
+}
+
 func ErrRequiredParam(w http.ResponseWriter, statusCode int, msg string) {
 	wrapError := WrapError{
 		ErrorCode: RequiredParam,
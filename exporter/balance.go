@@ -0,0 +1,199 @@
+package exporter
+
+import (
+	"encoding/json"
+	"strconv"
+	"sync"
+	"time"
+
+	"mintscan/models"
+	"mintscan/schema"
+)
+
+const (
+	balanceTxsCursor       = "balance txs"       // name of cursor that saves the last transaction id whose accounts are swept
+	balanceAddressesCursor = "balance addresses" // name of cursor that saves the last known address id that is swept
+	balanceTxsBatch        = 1000                // number of new transactions whose accounts are swept in a single run
+	balanceTxsChunk        = 100                 // number of transactions swept before the cursor is advanced
+	balanceAddressesBatch  = 200                 // number of known addresses swept in a single run
+	balanceStaleBatch      = 100                 // number of the largest stale balances refreshed in a single run
+	balanceStaleDuration   = time.Hour           // balances are refreshed when they are older than this
+	balanceConcurrency     = 10                  // max number of accounts requested at once
+)
+
+// syncBalances sweeps balances of accounts that are involved in new transactions, of known addresses
+// that are not swept yet and of the largest balances that are stale, since trades and transfers received
+// change balances without the account signing a transaction. Cursors are advanced after every chunk,
+// and an account is swept at most once in a run
+func (ex *Exporter) syncBalances() error {
+	swept := make(map[string]bool)
+
+	txsAfter, err := ex.db.QueryCursor(balanceTxsCursor)
+	if err != nil {
+		return err
+	}
+
+	// Known addresses cover accounts before the latest transaction when the rich list starts
+	if txsAfter == 0 {
+		latestID, err := ex.db.QueryLatestTxID()
+		if err != nil {
+			return err
+		}
+
+		err = ex.db.UpdateCursor(balanceTxsCursor, int64(latestID))
+		if err != nil {
+			return err
+		}
+		txsAfter = int64(latestID)
+	}
+
+	txs, err := ex.db.QueryTxsAfter(int32(txsAfter), balanceTxsBatch)
+	if err != nil {
+		return err
+	}
+
+	for start := 0; start < len(txs); start += balanceTxsChunk {
+		end := start + balanceTxsChunk
+		if end > len(txs) {
+			end = len(txs)
+		}
+
+		addresses := make([]string, 0)
+		for _, tx := range txs[start:end] {
+			addresses = append(addresses, txAddresses(tx)...)
+		}
+
+		err = ex.sweepBalances(addresses, swept)
+		if err != nil {
+			return err
+		}
+
+		err = ex.db.UpdateCursor(balanceTxsCursor, int64(txs[end-1].ID))
+		if err != nil {
+			return err
+		}
+	}
+
+	addressesAfter, err := ex.db.QueryCursor(balanceAddressesCursor)
+	if err != nil {
+		return err
+	}
+
+	known, err := ex.db.QueryAddressesAfter(int32(addressesAfter), balanceAddressesBatch)
+	if err != nil {
+		return err
+	}
+
+	if len(known) > 0 {
+		addresses := make([]string, 0)
+		for _, address := range known {
+			addresses = append(addresses, address.Address)
+		}
+
+		err = ex.sweepBalances(addresses, swept)
+		if err != nil {
+			return err
+		}
+
+		err = ex.db.UpdateCursor(balanceAddressesCursor, int64(known[len(known)-1].ID))
+		if err != nil {
+			return err
+		}
+	}
+
+	stale, err := ex.db.QueryStaleBalanceAddresses(time.Now().UTC().Add(-balanceStaleDuration), balanceStaleBatch)
+	if err != nil {
+		return err
+	}
+
+	return ex.sweepBalances(stale, swept)
+}
+
+// sweepBalances requests accounts of the given addresses concurrently and replaces their saved balances.
+// Addresses that are already swept are skipped and swept addresses are added to swept. Accounts that
+// fail to be requested are skipped, while failing to save balances fails the sweep
+func (ex *Exporter) sweepBalances(addresses []string, swept map[string]bool) error {
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var sweepErr error
+	sem := make(chan struct{}, balanceConcurrency)
+
+	for _, address := range addresses {
+		if address == "" || swept[address] {
+			continue
+		}
+		swept[address] = true
+
+		wg.Add(1)
+		go func(address string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			account, err := ex.client.Account(address)
+			if err != nil {
+				ex.l.Error("failed to request account", "address", address, "err", err)
+				return
+			}
+
+			balances := make([]schema.AccountBalance, 0)
+			for _, balance := range account.Balances {
+				free, _ := strconv.ParseFloat(balance.Free, 64)
+				locked, _ := strconv.ParseFloat(balance.Locked, 64)
+				frozen, _ := strconv.ParseFloat(balance.Frozen, 64)
+
+				balances = append(balances, schema.AccountBalance{
+					Address: address,
+					Asset:   balance.Symbol,
+					Free:    free,
+					Locked:  locked,
+					Frozen:  frozen,
+					Total:   free + locked + frozen,
+				})
+			}
+
+			err = ex.db.ReplaceAccountBalances(address, balances)
+			if err != nil {
+				mu.Lock()
+				sweepErr = err
+				mu.Unlock()
+			}
+		}(address)
+	}
+
+	wg.Wait()
+
+	return sweepErr
+}
+
+// txAddresses returns signers of a transaction and recipients of transfers in it
+func txAddresses(tx schema.Transaction) []string {
+	addresses := make([]string, 0)
+
+	sigs := make([]models.Signature, 0)
+	if json.Unmarshal([]byte(tx.Signatures), &sigs) == nil {
+		for _, sig := range sigs {
+			addresses = append(addresses, sig.Address)
+		}
+	}
+
+	msgs := make([]models.Message, 0)
+	if json.Unmarshal([]byte(tx.Messages), &msgs) == nil {
+		for _, msg := range msgs {
+			if msg.Type != "cosmos-sdk/Send" {
+				continue
+			}
+
+			var value models.SendMsgValue
+			if json.Unmarshal(msg.Value, &value) != nil {
+				continue
+			}
+
+			for _, output := range value.Outputs {
+				addresses = append(addresses, output.Address)
+			}
+		}
+	}
+
+	return addresses
+}
@@ -0,0 +1,76 @@
+package exporter
+
+import (
+	"time"
+
+	"mintscan/models"
+	"mintscan/schema"
+)
+
+const (
+	tradesLimit   = 1000      // max number of trades that API server returns at once
+	candlesCursor = "candles" // id of the last trade aggregated into candles
+)
+
+// syncTrades saves trades executed since the latest saved trade and aggregates them into candles.
+// Trades of the last 24 hours are fetched when trade table is empty
+func (ex *Exporter) syncTrades() error {
+	start, err := ex.db.QueryLatestTradeTime()
+	if err != nil {
+		start = time.Now().UTC().Add(-24 * time.Hour)
+	}
+
+	for offset := 0; ; offset += tradesLimit {
+		trades, err := ex.client.Trades(start.UnixNano()/int64(time.Millisecond), tradesLimit, offset)
+		if err != nil {
+			return err
+		}
+
+		rows := make([]schema.Trade, 0)
+		for _, trade := range trades.Trade {
+			rows = append(rows, schema.Trade{
+				TradeID:     trade.TradeID,
+				BlockHeight: trade.BlockHeight,
+				Symbol:      trade.Symbol,
+				Price:       trade.Price,
+				Quantity:    trade.Quantity,
+				Timestamp:   time.Unix(0, trade.Time*int64(time.Millisecond)).UTC(),
+			})
+		}
+
+		err = ex.db.InsertTrades(rows)
+		if err != nil {
+			return err
+		}
+
+		if len(trades.Trade) < tradesLimit {
+			break
+		}
+	}
+
+	return ex.aggregateCandles()
+}
+
+// aggregateCandles rebuilds candles in every interval that contain trades saved since the last run.
+// Trades are tracked by id rather than by time, so trades saved late with an earlier timestamp are
+// aggregated into the candles they belong to
+func (ex *Exporter) aggregateCandles() error {
+	cursor, err := ex.db.QueryCursor(candlesCursor)
+	if err != nil {
+		return err
+	}
+
+	latest, err := ex.db.QueryLatestTradeID()
+	if err != nil || latest <= cursor {
+		return err
+	}
+
+	for interval, duration := range models.CandleIntervals {
+		err = ex.db.UpsertCandlesFromTrades(interval, duration, cursor, latest)
+		if err != nil {
+			return err
+		}
+	}
+
+	return ex.db.UpdateCursor(candlesCursor, latest)
+}
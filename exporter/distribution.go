@@ -0,0 +1,85 @@
+package exporter
+
+import (
+	"encoding/json"
+	"time"
+
+	"mintscan/models"
+	"mintscan/schema"
+)
+
+// maxHolderPages is max number of asset holder pages of 100 holders requested for a snapshot
+const maxHolderPages = 100
+
+// snapshotAssetHolders takes hourly snapshots of holder distribution for featured assets
+// and assets that distribution is configured to be tracked for
+func (ex *Exporter) snapshotAssetHolders() error {
+	for _, asset := range ex.distributionAssets() {
+		latest, err := ex.db.QueryLatestAssetHolderSnapshot(asset)
+		if err == nil && time.Since(latest.Timestamp) < time.Hour {
+			continue
+		}
+
+		dist, err := ex.client.AssetDistribution(asset, maxHolderPages)
+		if err != nil {
+			ex.l.Error("failed to get asset holders", "asset", asset, "err", err)
+			continue
+		}
+
+		if dist.Holders <= 0 {
+			ex.l.Error("asset has no holders, skipping snapshot", "asset", asset)
+			continue
+		}
+
+		buckets, err := json.Marshal(dist.Buckets)
+		if err != nil {
+			return err
+		}
+
+		err = ex.db.InsertAssetHolderSnapshot(schema.AssetHolderSnapshot{
+			Asset:          asset,
+			Holders:        dist.Holders,
+			SampledHolders: dist.SampledHolders,
+			Gini:           dist.Gini,
+			Top10Share:     dist.Top10Share,
+			Top100Share:    dist.Top100Share,
+			Buckets:        string(buckets),
+			Timestamp:      time.Now().UTC(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// distributionAssets returns featured assets, which are the ones saved in database, configured ones
+// or the default ones in that priority, followed by assets that distribution is configured to be tracked for
+func (ex *Exporter) distributionAssets() []string {
+	featured, err := ex.db.QueryFeaturedAssets()
+	if err != nil {
+		ex.l.Error("failed to query featured assets", "err", err)
+	}
+
+	if len(featured) <= 0 {
+		featured = ex.asset.FeaturedAssets
+	}
+
+	if len(featured) <= 0 {
+		featured = models.ChosenAssetNames
+	}
+
+	assets := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, names := range [][]string{featured, ex.asset.DistributionAssets} {
+		for _, asset := range names {
+			if !seen[asset] {
+				seen[asset] = true
+				assets = append(assets, asset)
+			}
+		}
+	}
+
+	return assets
+}
@@ -0,0 +1,51 @@
+package exporter
+
+import (
+	"time"
+
+	"mintscan/client"
+	"mintscan/config"
+	"mintscan/db"
+
+	"github.com/tendermint/tendermint/libs/log"
+)
+
+// Exporter runs background jobs that fetch data from the active chain
+// and aggregate them into tables managed by this service
+type Exporter struct {
+	l      log.Logger
+	client *client.Client
+	db     *db.Database
+	asset  config.AssetConfig
+}
+
+// NewExporter creates a new exporter with the given params
+func NewExporter(l log.Logger, client *client.Client, db *db.Database, asset config.AssetConfig) *Exporter {
+	return &Exporter{l, client, db, asset}
+}
+
+// Start starts all jobs in separate goroutines
+func (ex *Exporter) Start() {
+	go ex.run("trades", time.Minute, ex.syncTrades)
+	go ex.run("asset holders", 10*time.Minute, ex.snapshotAssetHolders)
+	go ex.run("swaps", 10*time.Second, ex.syncSwaps)
+	go ex.run("validators", time.Minute, ex.snapshotValidators)
+	go ex.run("network stats", 5*time.Minute, ex.rollupNetworkStats)
+	go ex.run("balances", time.Minute, ex.syncBalances)
+	go ex.run("supply events", 10*time.Second, ex.syncSupplyEvents)
+}
+
+// run executes a job immediately and then on every interval
+func (ex *Exporter) run(name string, interval time.Duration, job func() error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		err := job()
+		if err != nil {
+			ex.l.Error("failed to run job", "job", name, "err", err)
+		}
+
+		<-ticker.C
+	}
+}
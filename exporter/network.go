@@ -0,0 +1,148 @@
+package exporter
+
+import (
+	"encoding/json"
+	"time"
+
+	"mintscan/models"
+	"mintscan/schema"
+)
+
+const (
+	addressesCursor        = "addresses" // name of cursor that saves the last transaction id whose addresses are saved
+	addressesBatch         = 10000       // number of transactions whose addresses are saved at once
+	addressesMaxBatches    = 10          // max number of batches saved in a single run
+	networkStatsMaxBuckets = 24 * 7      // max number of buckets aggregated in a single run
+)
+
+// rollupNetworkStats aggregates blocks and transactions into network statistics in every interval.
+// The latest bucket of each interval is rebuilt since it may have been aggregated partially, and buckets
+// without any block are saved as well so that the rollup advances through them
+func (ex *Exporter) rollupNetworkStats() error {
+	syncedTo, err := ex.syncAddresses()
+	if err != nil {
+		return err
+	}
+
+	fees, err := ex.msgFees()
+	if err != nil {
+		return err
+	}
+
+	for interval, duration := range models.NetworkStatIntervals {
+		from, err := ex.db.QueryLatestNetworkStatTime(interval)
+		if err != nil {
+			from, err = ex.db.QueryEarliestBlockTime()
+			if err != nil {
+				return nil // no blocks are indexed yet
+			}
+		}
+
+		from = from.UTC().Truncate(duration)
+
+		// New addresses are counted only up to the transactions whose addresses are saved
+		to := from.Add(networkStatsMaxBuckets * duration)
+		if to.After(syncedTo) {
+			to = syncedTo
+		}
+
+		stats, err := ex.db.AggregateNetworkStats(interval, duration, from, to)
+		if err != nil {
+			return err
+		}
+
+		for i := range stats {
+			stats[i].EstAvgFee = estAvgFee(stats[i], fees)
+		}
+
+		err = ex.db.UpsertNetworkStats(stats)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// syncAddresses saves signers and recipients of transactions that are indexed since the last run with the
+// time they were first seen and returns the time up to which addresses are saved
+func (ex *Exporter) syncAddresses() (time.Time, error) {
+	after, err := ex.db.QueryCursor(addressesCursor)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var syncedTo time.Time
+
+	for i := 0; i < addressesMaxBatches; i++ {
+		txs, err := ex.db.QueryTxIDsAfter(int32(after), addressesBatch)
+		if err != nil {
+			return syncedTo, err
+		}
+
+		if len(txs) <= 0 {
+			return time.Now().UTC(), nil
+		}
+
+		last := txs[len(txs)-1]
+
+		err = ex.db.InsertAddresses(int32(after), last.ID)
+		if err != nil {
+			return syncedTo, err
+		}
+
+		err = ex.db.UpdateCursor(addressesCursor, int64(last.ID))
+		if err != nil {
+			return syncedTo, err
+		}
+
+		if len(txs) < addressesBatch {
+			return time.Now().UTC(), nil
+		}
+
+		after = int64(last.ID)
+		syncedTo = last.Timestamp.UTC()
+	}
+
+	return syncedTo, nil
+}
+
+// msgFees returns current fees of message types in fee parameters
+func (ex *Exporter) msgFees() (map[string]int64, error) {
+	fees, err := ex.client.TxMsgFees()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]int64)
+	for _, fee := range fees {
+		switch {
+		case fee.FixedFeeParams != nil:
+			result[fee.FixedFeeParams.MsgType] = int64(fee.FixedFeeParams.Fee)
+		case fee.MsgType != "":
+			result[fee.MsgType] = int64(fee.Fee)
+		}
+	}
+
+	return result, nil
+}
+
+// estAvgFee estimates average fee per transaction from the number of messages of each type.
+// Indexed transactions don't carry the fees they paid, so fees are estimated with current fee parameters
+func estAvgFee(stat schema.NetworkStat, fees map[string]int64) float64 {
+	if stat.NumTxs <= 0 {
+		return 0
+	}
+
+	msgTypes := make(map[string]int64)
+	if json.Unmarshal([]byte(stat.MsgTypes), &msgTypes) != nil {
+		return 0
+	}
+
+	var total int64
+	for msgType, num := range msgTypes {
+		total += num * fees[models.FeeMsgTypes[msgType]]
+	}
+
+	return float64(total) / 1e8 / float64(stat.NumTxs)
+}
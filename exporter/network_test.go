@@ -0,0 +1,37 @@
+package exporter
+
+import (
+	"testing"
+
+	"mintscan/schema"
+)
+
+func TestEstAvgFee(t *testing.T) {
+	fees := map[string]int64{
+		"send":     37500,
+		"issueMsg": 40000000000,
+		"timeLock": 1000000,
+	}
+
+	tests := []struct {
+		name string
+		stat schema.NetworkStat
+		want float64
+	}{
+		{"no txs", schema.NetworkStat{NumTxs: 0, MsgTypes: `{"cosmos-sdk/Send":2}`}, 0},
+		{"invalid msg types", schema.NetworkStat{NumTxs: 1, MsgTypes: `[]`}, 0},
+		{"sends", schema.NetworkStat{NumTxs: 2, MsgTypes: `{"cosmos-sdk/Send":2}`}, 0.000375},
+		{"mixed", schema.NetworkStat{NumTxs: 4, MsgTypes: `{"cosmos-sdk/Send":2,"tokens/IssueMsg":1,"tokens/TimeLockMsg":1}`}, 100.0026875},
+		{"multi-message txs", schema.NetworkStat{NumTxs: 1, MsgTypes: `{"cosmos-sdk/Send":4}`}, 0.0015},
+		{"msgs without fee", schema.NetworkStat{NumTxs: 2, MsgTypes: `{"dex/NewOrder":2}`}, 0},
+		{"unknown fee parameter", schema.NetworkStat{NumTxs: 1, MsgTypes: `{"tokens/BurnMsg":1}`}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := estAvgFee(tt.stat, fees); got != tt.want {
+				t.Errorf("estAvgFee() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
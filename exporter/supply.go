@@ -0,0 +1,282 @@
+package exporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"mintscan/models"
+	"mintscan/schema"
+	"mintscan/utils"
+)
+
+// supplyEventsCursor is the name of cursor that saves the last processed transaction id for supply events
+const supplyEventsCursor = "supply events"
+
+// timeLockKey identifies a time lock of an account
+type timeLockKey struct {
+	address string
+	id      int64
+}
+
+// supplySync holds running totals of assets and active time locks of accounts that are loaded
+// while supply events are synced
+type supplySync struct {
+	ex     *Exporter
+	totals map[string]schema.SupplyEvent      // the latest event of each asset
+	locks  map[string]map[int64][]models.Coin // active time locks of each account
+}
+
+// syncSupplyEvents saves supply-changing events of every asset in indexed transactions with running totals,
+// so that they don't have to be reconstructed from the entire transaction history on request
+func (ex *Exporter) syncSupplyEvents() error {
+	after, err := ex.db.QueryCursor(supplyEventsCursor)
+	if err != nil {
+		return err
+	}
+
+	filters := []string{
+		utils.MsgFilter("tokens/IssueMsg", nil),
+		utils.MsgFilter("tokens/MiniIssueMsg", nil),
+		utils.MsgFilter("tokens/TinyIssueMsg", nil),
+		utils.MsgFilter("tokens/MintMsg", nil),
+		utils.MsgFilter("tokens/BurnMsg", nil),
+		utils.MsgFilter("tokens/FreezeMsg", nil),
+		utils.MsgFilter("tokens/UnfreezeMsg", nil),
+		utils.MsgFilter("tokens/TimeLockMsg", nil),
+		utils.MsgFilter("tokens/TimeRelockMsg", nil),
+		utils.MsgFilter("tokens/TimeUnlockMsg", nil),
+	}
+
+	txs, err := ex.db.QueryTxsByMsgFiltersAfter(filters, after, 1000)
+	if err != nil {
+		return err
+	}
+
+	s := &supplySync{
+		ex:     ex,
+		totals: make(map[string]schema.SupplyEvent),
+		locks:  make(map[string]map[int64][]models.Coin),
+	}
+
+	for _, tx := range txs {
+		err = s.saveTx(tx)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// saveTx saves supply events and time lock changes of a transaction
+func (s *supplySync) saveTx(tx schema.Transaction) error {
+	msgs := make([]models.Message, 0)
+	err := json.Unmarshal([]byte(tx.Messages), &msgs)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal msgs: %s", err)
+	}
+
+	events := make([]schema.SupplyEvent, 0)
+	changed := make([]timeLockKey, 0)
+
+	for _, msg := range msgs {
+		switch msg.Type {
+		case "tokens/IssueMsg", "tokens/MiniIssueMsg", "tokens/TinyIssueMsg":
+			var value models.IssueMsgValue
+			err = json.Unmarshal(msg.Value, &value)
+			if err != nil {
+				return fmt.Errorf("failed to unmarshal %s: %s", msg.Type, err)
+			}
+
+			// Issued token symbol is suffixed with the first 3 characters of its issue tx hash,
+			// followed by M for mini tokens
+			asset := value.Symbol + "-" + strings.ToUpper(tx.TxHash[:3])
+			if msg.Type != "tokens/IssueMsg" {
+				asset += "M"
+			}
+
+			event, err := s.event(tx, asset, models.SupplyEventIssue, value.From, value.TotalSupply)
+			if err != nil {
+				return err
+			}
+			events = append(events, event)
+
+		case "tokens/MintMsg", "tokens/BurnMsg", "tokens/FreezeMsg", "tokens/UnfreezeMsg":
+			var value models.TokenAmountMsgValue
+			err = json.Unmarshal(msg.Value, &value)
+			if err != nil {
+				return fmt.Errorf("failed to unmarshal %s: %s", msg.Type, err)
+			}
+
+			eventType := map[string]string{
+				"tokens/MintMsg":     models.SupplyEventMint,
+				"tokens/BurnMsg":     models.SupplyEventBurn,
+				"tokens/FreezeMsg":   models.SupplyEventFreeze,
+				"tokens/UnfreezeMsg": models.SupplyEventUnfreeze,
+			}[msg.Type]
+
+			event, err := s.event(tx, value.Symbol, eventType, value.From, value.Amount)
+			if err != nil {
+				return err
+			}
+			events = append(events, event)
+
+		case "tokens/TimeLockMsg", "tokens/TimeRelockMsg", "tokens/TimeUnlockMsg":
+			var value models.TimeLockMsgValue
+			err = json.Unmarshal(msg.Value, &value)
+			if err != nil {
+				return fmt.Errorf("failed to unmarshal %s: %s", msg.Type, err)
+			}
+
+			locks, err := s.activeLocks(value.From)
+			if err != nil {
+				return err
+			}
+
+			var eventType string
+			var delta []models.Coin
+			id := value.ID
+
+			switch msg.Type {
+			case "tokens/TimeLockMsg":
+				// Lock ids are assigned the same way as the chain does, which is the largest id among active locks plus one
+				id = 0
+				for activeID := range locks {
+					if activeID > id {
+						id = activeID
+					}
+				}
+				id++
+
+				eventType, delta = models.SupplyEventTimeLock, value.Amount
+				locks[id] = value.Amount
+
+			case "tokens/TimeRelockMsg":
+				amount, ok := locks[id]
+				if !ok || len(value.Amount) <= 0 {
+					continue
+				}
+
+				eventType, delta = models.SupplyEventTimeRelock, models.SubCoins(value.Amount, amount)
+				locks[id] = value.Amount
+
+			case "tokens/TimeUnlockMsg":
+				amount, ok := locks[id]
+				if !ok {
+					continue
+				}
+
+				eventType, delta = models.SupplyEventTimeUnlock, models.SubCoins(nil, amount)
+				delete(locks, id)
+			}
+
+			changed = append(changed, timeLockKey{value.From, id})
+
+			for _, coin := range delta {
+				event, err := s.event(tx, coin.Denom, eventType, value.From, coin.Amount)
+				if err != nil {
+					return err
+				}
+				events = append(events, event)
+			}
+		}
+	}
+
+	// Each changed time lock is saved in its state after the transaction
+	locks := make([]schema.TimeLock, 0)
+	unlocked := make([]schema.TimeLock, 0)
+	saved := make(map[timeLockKey]bool)
+
+	for _, key := range changed {
+		if saved[key] {
+			continue
+		}
+		saved[key] = true
+
+		amount, ok := s.locks[key.address][key.id]
+		if !ok {
+			unlocked = append(unlocked, schema.TimeLock{Address: key.address, LockID: key.id})
+			continue
+		}
+
+		bz, err := json.Marshal(amount)
+		if err != nil {
+			return err
+		}
+
+		locks = append(locks, schema.TimeLock{Address: key.address, LockID: key.id, Amount: string(bz)})
+	}
+
+	return s.ex.db.SaveSupplyEvents(events, locks, unlocked, supplyEventsCursor, int64(tx.ID))
+}
+
+// event returns a supply event of an asset with running totals after the event applied to the latest event of the asset
+func (s *supplySync) event(tx schema.Transaction, asset string, eventType string, from string, amount int64) (schema.SupplyEvent, error) {
+	latest, ok := s.totals[asset]
+	if !ok {
+		var err error
+		latest, err = s.ex.db.QueryLatestSupplyEvent(asset)
+		if err != nil {
+			return schema.SupplyEvent{}, err
+		}
+	}
+
+	event := schema.SupplyEvent{
+		Asset:       asset,
+		Type:        eventType,
+		Height:      tx.Height,
+		TxID:        tx.ID,
+		TxHash:      tx.TxHash,
+		From:        from,
+		Amount:      amount,
+		TotalSupply: latest.TotalSupply,
+		Frozen:      latest.Frozen,
+		Locked:      latest.Locked,
+		Timestamp:   tx.Timestamp,
+	}
+
+	switch eventType {
+	case models.SupplyEventIssue, models.SupplyEventMint:
+		event.TotalSupply += amount
+	case models.SupplyEventBurn:
+		event.TotalSupply -= amount
+	case models.SupplyEventFreeze:
+		event.Frozen += amount
+	case models.SupplyEventUnfreeze:
+		event.Frozen -= amount
+	case models.SupplyEventTimeLock, models.SupplyEventTimeRelock, models.SupplyEventTimeUnlock:
+		event.Locked += amount
+	}
+
+	s.totals[asset] = event
+
+	return event, nil
+}
+
+// activeLocks returns amounts of active time locks of an account by lock id
+func (s *supplySync) activeLocks(address string) (map[int64][]models.Coin, error) {
+	if locks, ok := s.locks[address]; ok {
+		return locks, nil
+	}
+
+	saved, err := s.ex.db.QueryTimeLocks(address)
+	if err != nil {
+		return nil, err
+	}
+
+	locks := make(map[int64][]models.Coin)
+	for _, lock := range saved {
+		amount := make([]models.Coin, 0)
+		err = json.Unmarshal([]byte(lock.Amount), &amount)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal time lock amount: %s", err)
+		}
+
+		locks[lock.LockID] = amount
+	}
+
+	s.locks[address] = locks
+
+	return locks, nil
+}
@@ -0,0 +1,152 @@
+package exporter
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"mintscan/models"
+	"mintscan/schema"
+	"mintscan/utils"
+
+	"github.com/binance-chain/go-sdk/common/bech32"
+	"github.com/binance-chain/go-sdk/common/types"
+	"github.com/binance-chain/go-sdk/types/msg"
+)
+
+// swapsCursor is the name of cursor that saves the last processed transaction id for atomic swaps
+const swapsCursor = "swaps"
+
+// syncSwaps links HTLT messages in indexed transactions with their deposits, claims and refunds
+func (ex *Exporter) syncSwaps() error {
+	after, err := ex.db.QueryCursor(swapsCursor)
+	if err != nil {
+		return err
+	}
+
+	filters := []string{
+		utils.MsgFilter("tokens/HTLTMsg", nil),
+		utils.MsgFilter("tokens/DepositHTLTMsg", nil),
+		utils.MsgFilter("tokens/ClaimHTLTMsg", nil),
+		utils.MsgFilter("tokens/RefundHTLTMsg", nil),
+	}
+
+	txs, err := ex.db.QueryTxsByMsgFiltersAfter(filters, after, 1000)
+	if err != nil {
+		return err
+	}
+
+	for _, tx := range txs {
+		err = ex.saveSwapMsgs(tx)
+		if err != nil {
+			return err
+		}
+
+		err = ex.db.UpdateCursor(swapsCursor, int64(tx.ID))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// saveSwapMsgs saves atomic swap messages in a transaction
+func (ex *Exporter) saveSwapMsgs(tx schema.Transaction) error {
+	msgs := make([]models.Message, 0)
+	err := json.Unmarshal([]byte(tx.Messages), &msgs)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal msgs: %s", err)
+	}
+
+	for _, m := range msgs {
+		switch m.Type {
+		case "tokens/HTLTMsg":
+			var value models.HTLTMsgValue
+			err = json.Unmarshal(m.Value, &value)
+			if err != nil {
+				return fmt.Errorf("failed to unmarshal HTLT msg: %s", err)
+			}
+
+			swapID, err := swapID(value)
+			if err != nil {
+				ex.l.Error("failed to calculate swap id", "tx_hash", tx.TxHash, "err", err)
+				continue
+			}
+
+			amount, _ := json.Marshal(value.Amount)
+
+			err = ex.db.InsertAtomicSwap(schema.AtomicSwap{
+				SwapID:              swapID,
+				From:                value.From,
+				To:                  value.To,
+				RecipientOtherChain: value.RecipientOtherChain,
+				SenderOtherChain:    value.SenderOtherChain,
+				RandomNumberHash:    value.RandomNumberHash,
+				SwapTimestamp:       value.Timestamp,
+				Amount:              string(amount),
+				ExpectedIncome:      value.ExpectedIncome,
+				HeightSpan:          value.HeightSpan,
+				CrossChain:          value.CrossChain,
+				Status:              models.SwapStatusOpen,
+				Height:              tx.Height,
+				TxHash:              tx.TxHash,
+				Timestamp:           tx.Timestamp,
+			})
+			if err != nil {
+				return err
+			}
+
+		case "tokens/DepositHTLTMsg", "tokens/ClaimHTLTMsg", "tokens/RefundHTLTMsg":
+			var value models.HTLTActionMsgValue
+			err = json.Unmarshal(m.Value, &value)
+			if err != nil {
+				return fmt.Errorf("failed to unmarshal %s: %s", m.Type, err)
+			}
+
+			swapID := strings.ToLower(value.SwapID)
+
+			switch m.Type {
+			case "tokens/DepositHTLTMsg":
+				amount, _ := json.Marshal(value.Amount)
+				err = ex.db.InsertAtomicSwapDeposit(schema.AtomicSwapDeposit{
+					SwapID:    swapID,
+					From:      value.From,
+					Amount:    string(amount),
+					Height:    tx.Height,
+					TxHash:    tx.TxHash,
+					Timestamp: tx.Timestamp,
+				})
+			case "tokens/ClaimHTLTMsg":
+				err = ex.db.UpdateAtomicSwapClaim(swapID, value.RandomNumber, tx.TxHash, tx.Height)
+			case "tokens/RefundHTLTMsg":
+				err = ex.db.UpdateAtomicSwapRefund(swapID, tx.TxHash, tx.Height)
+			}
+
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// swapID calculates swap id of an HTLT message the same way as the chain does,
+// which is the hash of random number hash, sender address and sender address on the other chain
+func swapID(value models.HTLTMsgValue) (string, error) {
+	randomNumberHash, err := hex.DecodeString(value.RandomNumberHash)
+	if err != nil {
+		return "", err
+	}
+
+	_, from, err := bech32.DecodeAndConvert(value.From)
+	if err != nil {
+		return "", err
+	}
+
+	id := msg.CalculateSwapID(randomNumberHash, types.AccAddress(from), value.SenderOtherChain)
+
+	return hex.EncodeToString(id), nil
+}
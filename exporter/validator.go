@@ -0,0 +1,72 @@
+package exporter
+
+import (
+	"time"
+
+	"mintscan/schema"
+)
+
+// snapshotValidators saves a snapshot of every validator whose state has changed since its latest snapshot
+func (ex *Exporter) snapshotValidators() error {
+	vals, err := ex.client.Validators()
+	if err != nil {
+		return err
+	}
+
+	height, err := ex.client.LatestBlockHeight()
+	if err != nil {
+		return err
+	}
+
+	latest, err := ex.db.QueryLatestValidatorSnapshots()
+	if err != nil {
+		return err
+	}
+
+	latestByOperator := make(map[string]schema.ValidatorSnapshot)
+	for _, snapshot := range latest {
+		latestByOperator[snapshot.OperatorAddress] = snapshot
+	}
+
+	snapshots := make([]schema.ValidatorSnapshot, 0)
+	for _, val := range vals {
+		snapshot := schema.ValidatorSnapshot{
+			OperatorAddress:         val.OperatorAddress,
+			Height:                  height,
+			Jailed:                  val.Jailed,
+			Status:                  val.Status,
+			Moniker:                 val.Description.Moniker,
+			Identity:                val.Description.Identity,
+			Website:                 val.Description.Website,
+			Details:                 val.Description.Details,
+			CommissionRate:          val.Commission.Rate,
+			CommissionMaxRate:       val.Commission.MaxRate,
+			CommissionMaxChangeRate: val.Commission.MaxChangeRate,
+			CommissionUpdateTime:    val.Commission.UpdateTime.UTC(),
+			Timestamp:               time.Now().UTC(),
+		}
+
+		prev, ok := latestByOperator[val.OperatorAddress]
+		if ok && !validatorChanged(prev, snapshot) {
+			continue
+		}
+
+		snapshots = append(snapshots, snapshot)
+	}
+
+	return ex.db.InsertValidatorSnapshots(snapshots)
+}
+
+// validatorChanged returns true if jailing, status, description or commission differ between snapshots
+func validatorChanged(a schema.ValidatorSnapshot, b schema.ValidatorSnapshot) bool {
+	return a.Jailed != b.Jailed ||
+		a.Status != b.Status ||
+		a.Moniker != b.Moniker ||
+		a.Identity != b.Identity ||
+		a.Website != b.Website ||
+		a.Details != b.Details ||
+		a.CommissionRate != b.CommissionRate ||
+		a.CommissionMaxRate != b.CommissionMaxRate ||
+		a.CommissionMaxChangeRate != b.CommissionMaxChangeRate ||
+		!a.CommissionUpdateTime.Equal(b.CommissionUpdateTime)
+}
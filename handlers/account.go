@@ -2,34 +2,43 @@ package handlers
 
 import (
 	"encoding/json"
-	"log"
 	"net/http"
 	"strconv"
+	"time"
 
 	"mintscan/client"
+	"mintscan/codec"
 	"mintscan/db"
 	"mintscan/errors"
 	"mintscan/models"
 	"mintscan/utils"
 
 	"github.com/gorilla/mux"
+
+	"github.com/binance-chain/go-sdk/common/bech32"
+	cmtypes "github.com/binance-chain/go-sdk/common/types"
+	"github.com/tendermint/tendermint/crypto"
+	"github.com/tendermint/tendermint/crypto/ed25519"
+	"github.com/tendermint/tendermint/crypto/multisig"
+	"github.com/tendermint/tendermint/crypto/secp256k1"
+	"github.com/tendermint/tendermint/libs/log"
 )
 
 // Account is a account handler
 type Account struct {
-	l      *log.Logger
+	l      log.Logger
 	client *client.Client
 	db     *db.Database
+	nt     cmtypes.ChainNetwork
 }
 
 // NewAccount creates a new account handler with the given params
-func NewAccount(l *log.Logger, client *client.Client, db *db.Database) *Account {
-	return &Account{l, client, db}
+func NewAccount(l log.Logger, client *client.Client, db *db.Database, network cmtypes.ChainNetwork) *Account {
+	return &Account{l, client, db, network}
 }
 
 // GetAccount returns account information
 func (a *Account) GetAccount(rw http.ResponseWriter, r *http.Request) {
-	rw.Header().Set("Access-Control-Allow-Origin", "*")
 	vars := mux.Vars(r)
 	address := vars["address"]
 
@@ -38,24 +47,166 @@ func (a *Account) GetAccount(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if len(address) != 42 {
+	if !utils.IsAccAddress(a.nt, address) {
 		errors.ErrInvalidParam(rw, http.StatusBadRequest, "address is invalid")
 		return
 	}
 
-	account, err := a.client.Account(address)
+	account, err := a.client.WithContext(r.Context()).Account(address)
 	if err != nil {
-		a.l.Printf("failed to request account information: %s\n", err)
+		a.l.Error("failed to request account information", "err", err)
+	}
+
+	result := &models.ResultAccount{
+		Account:   account,
+		Label:     addressLabels(r.Context(), a.l, a.db, address)[address],
+		FlagNames: models.AccountFlagNames(account.Flags),
+		Type:      models.AccountTypeStandard,
+	}
+
+	if len(account.PublicKey) > 0 && string(account.PublicKey) != "null" {
+		result.PubKey, err = a.decodePubKey(account.PublicKey)
+		if err != nil {
+			a.l.Error("failed to decode public key", "err", err)
+		}
+	}
+
+	// accounts with flags set have scripts checked by the chain on every transfer to them
+	switch {
+	case result.PubKey != nil && result.PubKey.Type == multisig.PubKeyMultisigThresholdAminoRoute:
+		result.Type = models.AccountTypeMultisig
+	case account.Flags != 0:
+		result.Type = models.AccountTypeScript
+	}
+
+	utils.Respond(rw, result)
+	return
+}
+
+// GetAccountFlags returns current flags of an account with the history of flag changes
+func (a *Account) GetAccountFlags(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	address := vars["address"]
+
+	if address == "" {
+		errors.ErrRequiredParam(rw, http.StatusBadRequest, "address is required")
+		return
+	}
+
+	if !utils.IsAccAddress(a.nt, address) {
+		errors.ErrInvalidParam(rw, http.StatusBadRequest, "address is invalid")
+		return
+	}
+
+	account, err := a.client.WithContext(r.Context()).Account(address)
+	if err != nil {
+		a.l.Error("failed to request account information", "err", err)
+	}
+
+	filters := []string{utils.MsgFilter("scripts/SetAccountFlagsMsg", map[string]string{"from": address})}
+
+	txs, err := a.db.WithContext(r.Context()).QueryTxsByMsgFilters(filters, 0, 1000)
+	if err != nil {
+		a.l.Error("failed to query set account flags txs", "err", err)
+	}
+
+	result := &models.ResultAccountFlags{
+		Address:   address,
+		Flags:     account.Flags,
+		FlagNames: models.AccountFlagNames(account.Flags),
+		History:   make([]models.FlagsChange, 0),
+	}
+
+	for _, tx := range txs {
+		for _, msg := range unmarshalMsgs(a.l, tx, "scripts/SetAccountFlagsMsg") {
+			var value models.SetAccountFlagsMsgValue
+			if json.Unmarshal(msg.Value, &value) != nil || value.From != address {
+				continue
+			}
+
+			result.History = append(result.History, models.FlagsChange{
+				Flags:     value.Flags,
+				FlagNames: models.AccountFlagNames(value.Flags),
+				Height:    tx.Height,
+				TxHash:    tx.TxHash,
+				Timestamp: tx.Timestamp,
+			})
+		}
 	}
 
-	utils.Respond(rw, account)
+	utils.Respond(rw, result)
 	return
 }
 
+// decodePubKey decodes public key of an account returned by the API, which is either a raw
+// compressed secp256k1 key or an amino encoded key, into its type and bech32 representation
+func (a *Account) decodePubKey(raw json.RawMessage) (*models.PubKeyInfo, error) {
+	var bz []byte
+
+	// The API returns public key as an array of bytes, fall back to base64 encoded string
+	var ints []int
+	if err := json.Unmarshal(raw, &ints); err == nil {
+		bz = make([]byte, len(ints))
+		for i, b := range ints {
+			bz[i] = byte(b)
+		}
+	} else if err := json.Unmarshal(raw, &bz); err != nil {
+		return nil, err
+	}
+
+	var pubKey crypto.PubKey
+	if err := codec.Codec.UnmarshalBinaryBare(bz, &pubKey); err != nil {
+		switch len(bz) {
+		case secp256k1.PubKeySecp256k1Size:
+			var key secp256k1.PubKeySecp256k1
+			copy(key[:], bz)
+			pubKey = key
+		case ed25519.PubKeyEd25519Size:
+			var key ed25519.PubKeyEd25519
+			copy(key[:], bz)
+			pubKey = key
+		default:
+			return nil, err
+		}
+	}
+
+	return a.pubKeyInfo(pubKey)
+}
+
+// pubKeyInfo returns type and bech32 representation of a public key. Sub keys and
+// threshold of a multisig public key are decoded as well
+func (a *Account) pubKeyInfo(pubKey crypto.PubKey) (*models.PubKeyInfo, error) {
+	info := &models.PubKeyInfo{}
+	switch key := pubKey.(type) {
+	case secp256k1.PubKeySecp256k1:
+		info.Type = secp256k1.PubKeyAminoName
+	case ed25519.PubKeyEd25519:
+		info.Type = ed25519.PubKeyAminoName
+	case multisig.PubKeyMultisigThreshold:
+		info.Type = multisig.PubKeyMultisigThresholdAminoRoute
+		info.Threshold = key.K
+		for _, subKey := range key.PubKeys {
+			subInfo, err := a.pubKeyInfo(subKey)
+			if err != nil {
+				return info, err
+			}
+			info.PubKeys = append(info.PubKeys, *subInfo)
+		}
+	default:
+		info.Type = "unknown"
+	}
+
+	bech32PubKey, err := bech32.ConvertAndEncode(a.nt.Bech32Prefixes()+"p", codec.Codec.MustMarshalBinaryBare(pubKey))
+	if err != nil {
+		return info, err
+	}
+	info.Bech32 = bech32PubKey
+
+	return info, nil
+}
+
 // GetAccountTxs returns transactions associated with an account
 func (a *Account) GetAccountTxs(rw http.ResponseWriter, r *http.Request) {
-	rw.Header().Set("Access-Control-Allow-Origin", "*")
-
 	vars := mux.Vars(r)
 	address := vars["address"]
 
@@ -64,7 +215,7 @@ func (a *Account) GetAccountTxs(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if len(address) != 42 {
+	if !utils.IsAccAddress(a.nt, address) {
 		errors.ErrInvalidParam(rw, http.StatusBadRequest, "address is invalid")
 		return
 	}
@@ -90,9 +241,9 @@ func (a *Account) GetAccountTxs(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	acctTxs, err := a.client.AccountTxs(address, page, rows)
+	acctTxs, err := a.client.WithContext(r.Context()).AccountTxs(address, page, rows)
 	if err != nil {
-		a.l.Printf("failed to get account txs: %s\n", err)
+		a.l.Error("failed to get account txs", "err", err)
 	}
 
 	txArray := make([]models.AccountTxArray, 0)
@@ -127,7 +278,7 @@ func (a *Account) GetAccountTxs(rw http.ResponseWriter, r *http.Request) {
 		if tx.Data != "" {
 			err = json.Unmarshal([]byte(tx.Data), &data)
 			if err != nil {
-				a.l.Printf("failed to unmarshal AssetTxData: %s", err)
+				a.l.Error("failed to unmarshal AssetTxData", "err", err)
 			}
 
 			tempTxArray.Message = &data
@@ -136,6 +287,17 @@ func (a *Account) GetAccountTxs(rw http.ResponseWriter, r *http.Request) {
 		txArray = append(txArray, *tempTxArray)
 	}
 
+	addresses := make([]string, 0)
+	for _, tx := range txArray {
+		addresses = append(addresses, tx.FromAddr, tx.ToAddr)
+	}
+
+	labels := addressLabels(r.Context(), a.l, a.db, addresses...)
+	for i := range txArray {
+		txArray[i].FromAddrLabel = labels[txArray[i].FromAddr]
+		txArray[i].ToAddrLabel = labels[txArray[i].ToAddr]
+	}
+
 	result := &models.ResultAccountTxs{
 		TxNums:  acctTxs.TxNums,
 		TxArray: txArray,
@@ -144,3 +306,162 @@ func (a *Account) GetAccountTxs(rw http.ResponseWriter, r *http.Request) {
 	utils.Respond(rw, result)
 	return
 }
+
+// GetAccountTimeLocks returns time locks of an account reconstructed from its time lock transactions
+// with active locked totals cross-checked against locked balances of the account
+func (a *Account) GetAccountTimeLocks(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	address := vars["address"]
+
+	if address == "" {
+		errors.ErrRequiredParam(rw, http.StatusBadRequest, "address is required")
+		return
+	}
+
+	if !utils.IsAccAddress(a.nt, address) {
+		errors.ErrInvalidParam(rw, http.StatusBadRequest, "address is invalid")
+		return
+	}
+
+	locks, err := queryTimeLocks(a.db.WithContext(r.Context()), address)
+	if err != nil {
+		a.l.Error("failed to reconstruct time locks", "err", err)
+		errors.ErrInternalServer(rw, http.StatusInternalServerError)
+		return
+	}
+
+	now := time.Now().UTC()
+	timeLocked := make(map[string]int64)
+	denoms := make([]string, 0)
+
+	result := &models.ResultTimeLocks{
+		Address:   address,
+		Totals:    make([]models.TimeLockTotal, 0),
+		TimeLocks: make([]models.TimeLock, 0),
+	}
+
+	for _, lock := range locks {
+		if lock.Status == models.TimeLockStatusLocked {
+			lock.Unlockable = !now.Before(lock.LockTime)
+
+			for _, coin := range lock.Amount {
+				if _, ok := timeLocked[coin.Denom]; !ok {
+					denoms = append(denoms, coin.Denom)
+				}
+				timeLocked[coin.Denom] += coin.Amount
+			}
+		}
+
+		result.TimeLocks = append(result.TimeLocks, *lock)
+	}
+
+	account, err := a.client.WithContext(r.Context()).Account(address)
+	if err != nil {
+		a.l.Error("failed to request account information", "err", err)
+	}
+
+	accountLocked := make(map[string]float64)
+	for _, balance := range account.Balances {
+		accountLocked[balance.Symbol], _ = strconv.ParseFloat(balance.Locked, 64)
+	}
+
+	for _, denom := range denoms {
+		total := models.TimeLockTotal{
+			Denom:         denom,
+			TimeLocked:    float64(timeLocked[denom]) / 1e8,
+			AccountLocked: accountLocked[denom],
+		}
+		total.Difference = total.AccountLocked - total.TimeLocked
+
+		result.Totals = append(result.Totals, total)
+	}
+
+	utils.Respond(rw, result)
+	return
+}
+
+// GetTopAccounts returns the largest accounts of an asset with their share of total supply
+// and balance changes in the last 24 hours
+func (a *Account) GetTopAccounts(rw http.ResponseWriter, r *http.Request) {
+	asset := "BNB"
+	limit := int(100)
+
+	if len(r.URL.Query()["asset"]) > 0 {
+		asset = r.URL.Query()["asset"][0]
+	}
+
+	if len(r.URL.Query()["limit"]) > 0 {
+		limit, _ = strconv.Atoi(r.URL.Query()["limit"][0])
+	}
+
+	if limit < 1 {
+		errors.ErrInvalidParam(rw, http.StatusBadRequest, "'limit' cannot be less than 1")
+		return
+	}
+
+	if limit > 100 {
+		errors.ErrOverMaxLimit(rw, http.StatusUnauthorized)
+		return
+	}
+
+	balances, err := a.db.WithContext(r.Context()).QueryTopAccountBalances(asset, limit)
+	if err != nil {
+		a.l.Error("failed to query top account balances", "err", err)
+	}
+
+	result := &models.ResultTopAccounts{
+		Asset:    asset,
+		Accounts: make([]models.TopAccount, 0),
+	}
+
+	token, err := a.client.WithContext(r.Context()).Token(asset)
+	if err != nil {
+		a.l.Error("failed to request token", "err", err)
+	} else {
+		result.TotalSupply, _ = strconv.ParseFloat(token.TotalSupply, 64)
+	}
+
+	addresses := make([]string, 0)
+	for _, balance := range balances {
+		addresses = append(addresses, balance.Address)
+	}
+
+	prevTotals := make(map[string]float64)
+	history, err := a.db.WithContext(r.Context()).QueryAccountBalancesAt(asset, addresses, time.Now().UTC().Add(-24*time.Hour))
+	if err != nil {
+		a.l.Error("failed to query account balance history", "err", err)
+	}
+
+	for _, h := range history {
+		prevTotals[h.Address] = h.Total
+	}
+
+	labels := addressLabels(r.Context(), a.l, a.db, addresses...)
+
+	for i, balance := range balances {
+		account := models.TopAccount{
+			Rank:      i + 1,
+			Address:   balance.Address,
+			Label:     labels[balance.Address],
+			Free:      balance.Free,
+			Locked:    balance.Locked,
+			Frozen:    balance.Frozen,
+			Total:     balance.Total,
+			Timestamp: balance.Timestamp,
+		}
+
+		if result.TotalSupply > 0 {
+			account.Share = balance.Total / result.TotalSupply * 100
+		}
+
+		if prev, ok := prevTotals[balance.Address]; ok {
+			change := balance.Total - prev
+			account.Change24H = &change
+		}
+
+		result.Accounts = append(result.Accounts, account)
+	}
+
+	utils.Respond(rw, result)
+	return
+}
@@ -0,0 +1,376 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"mintscan/client"
+	"mintscan/db"
+	"mintscan/errors"
+	"mintscan/models"
+	"mintscan/schema"
+	"mintscan/utils"
+
+	"github.com/gorilla/mux"
+
+	cmtypes "github.com/binance-chain/go-sdk/common/types"
+	"github.com/tendermint/tendermint/libs/log"
+)
+
+// Admin is an admin handler
+type Admin struct {
+	l      log.Logger
+	client *client.Client
+	db     *db.Database
+	nt     cmtypes.ChainNetwork
+	token  string
+	tiers  []string // rate limit tiers that API keys can be issued for
+}
+
+// NewAdmin creates a new admin handler with the given params
+func NewAdmin(l log.Logger, client *client.Client, db *db.Database, network cmtypes.ChainNetwork, token string, tiers []string) *Admin {
+	return &Admin{l, client, db, network, token, tiers}
+}
+
+// authorized verifies admin token that is sent in Authorization header as a bearer token.
+// Every request is rejected when admin token is not configured
+func (a *Admin) authorized(r *http.Request) bool {
+	if a.token == "" {
+		return false
+	}
+
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+	return subtle.ConstantTimeCompare([]byte(token), []byte(a.token)) == 1
+}
+
+// GetFeaturedAssets returns featured assets saved in database
+func (a *Admin) GetFeaturedAssets(rw http.ResponseWriter, r *http.Request) {
+	if !a.authorized(r) {
+		errors.ErrNotAllowed(rw, http.StatusUnauthorized)
+		return
+	}
+
+	names, err := a.db.WithContext(r.Context()).QueryFeaturedAssets()
+	if err != nil {
+		a.l.Error("failed to query featured assets", "err", err)
+		errors.ErrInternalServer(rw, http.StatusInternalServerError)
+		return
+	}
+
+	utils.Respond(rw, &models.FeaturedAssets{Assets: names})
+	return
+}
+
+// PutFeaturedAssets replaces featured assets with the asset names in request body
+func (a *Admin) PutFeaturedAssets(rw http.ResponseWriter, r *http.Request) {
+	if !a.authorized(r) {
+		errors.ErrNotAllowed(rw, http.StatusUnauthorized)
+		return
+	}
+
+	var featured models.FeaturedAssets
+	err := json.NewDecoder(r.Body).Decode(&featured)
+	if err != nil {
+		errors.ErrFailedUnmarshalJSON(rw, http.StatusBadRequest)
+		return
+	}
+
+	// Duplicate names are saved once at the position where they first appear
+	names := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, name := range featured.Assets {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			errors.ErrInvalidParam(rw, http.StatusBadRequest, "asset name cannot be empty")
+			return
+		}
+
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	featured.Assets = names
+
+	err = a.db.WithContext(r.Context()).ReplaceFeaturedAssets(featured.Assets)
+	if err != nil {
+		a.l.Error("failed to replace featured assets", "err", err)
+		errors.ErrInternalServer(rw, http.StatusInternalServerError)
+		return
+	}
+
+	utils.Respond(rw, &featured)
+	return
+}
+
+// GetLabels returns every address label saved in database
+func (a *Admin) GetLabels(rw http.ResponseWriter, r *http.Request) {
+	if !a.authorized(r) {
+		errors.ErrNotAllowed(rw, http.StatusUnauthorized)
+		return
+	}
+
+	labels, err := a.db.WithContext(r.Context()).QueryAllLabels()
+	if err != nil {
+		a.l.Error("failed to query labels", "err", err)
+		errors.ErrInternalServer(rw, http.StatusInternalServerError)
+		return
+	}
+
+	result := &models.ResultLabels{
+		Total:  len(labels),
+		Labels: make([]models.Label, 0),
+	}
+
+	for _, label := range labels {
+		result.Labels = append(result.Labels, models.Label{
+			Address:  label.Address,
+			Label:    label.Label,
+			Category: label.Category,
+		})
+	}
+
+	utils.Respond(rw, result)
+	return
+}
+
+// PostLabels imports address labels from a JSON array or a CSV file with address, label and
+// optional category columns. Labels of addresses that are already labeled are overwritten and
+// the last entry of an address wins when it is listed more than once
+func (a *Admin) PostLabels(rw http.ResponseWriter, r *http.Request) {
+	if !a.authorized(r) {
+		errors.ErrNotAllowed(rw, http.StatusUnauthorized)
+		return
+	}
+
+	var labels []models.Label
+	var err error
+
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "text/csv") {
+		labels, err = parseLabelsCSV(r.Body)
+		if err != nil {
+			errors.ErrInvalidParam(rw, http.StatusBadRequest, err.Error())
+			return
+		}
+	} else {
+		err = json.NewDecoder(r.Body).Decode(&labels)
+		if err != nil {
+			errors.ErrFailedUnmarshalJSON(rw, http.StatusBadRequest)
+			return
+		}
+	}
+
+	index := make(map[string]int)
+	unique := make([]models.Label, 0)
+	for i, label := range labels {
+		label.Address = strings.TrimSpace(label.Address)
+		label.Label = strings.TrimSpace(label.Label)
+		label.Category = strings.TrimSpace(label.Category)
+
+		if label.Address == "" || label.Label == "" {
+			errors.ErrInvalidParam(rw, http.StatusBadRequest, fmt.Sprintf("address and label are required at entry %d", i))
+			return
+		}
+
+		if !utils.IsAccAddress(a.nt, label.Address) && !utils.IsValAddress(a.nt, label.Address) {
+			errors.ErrInvalidParam(rw, http.StatusBadRequest, fmt.Sprintf("address is invalid at entry %d", i))
+			return
+		}
+
+		if j, ok := index[label.Address]; ok {
+			unique[j] = label
+			continue
+		}
+		index[label.Address] = len(unique)
+		unique = append(unique, label)
+	}
+
+	rows := make([]schema.Label, 0)
+	for _, label := range unique {
+		rows = append(rows, schema.Label{
+			Address:  label.Address,
+			Label:    label.Label,
+			Category: label.Category,
+		})
+	}
+
+	err = a.db.WithContext(r.Context()).UpsertLabels(rows)
+	if err != nil {
+		a.l.Error("failed to import labels", "err", err)
+		errors.ErrInternalServer(rw, http.StatusInternalServerError)
+		return
+	}
+
+	utils.Respond(rw, &models.ResultLabels{Total: len(unique), Labels: unique})
+	return
+}
+
+// GetAPIKeys returns every API key saved in database without the keys themselves
+func (a *Admin) GetAPIKeys(rw http.ResponseWriter, r *http.Request) {
+	if !a.authorized(r) {
+		errors.ErrNotAllowed(rw, http.StatusUnauthorized)
+		return
+	}
+
+	keys, err := a.db.WithContext(r.Context()).QueryAPIKeys()
+	if err != nil {
+		a.l.Error("failed to query api keys", "err", err)
+		errors.ErrInternalServer(rw, http.StatusInternalServerError)
+		return
+	}
+
+	result := &models.ResultAPIKeys{
+		Total:   len(keys),
+		APIKeys: make([]models.APIKey, 0),
+	}
+
+	for _, key := range keys {
+		result.APIKeys = append(result.APIKeys, models.APIKey{
+			ID:        key.ID,
+			Name:      key.Name,
+			Tier:      key.Tier,
+			Disabled:  key.Disabled,
+			Timestamp: key.Timestamp,
+		})
+	}
+
+	utils.Respond(rw, result)
+	return
+}
+
+// PostAPIKey issues an API key with the name and rate limit tier in request body.
+// The key is only returned in this response
+func (a *Admin) PostAPIKey(rw http.ResponseWriter, r *http.Request) {
+	if !a.authorized(r) {
+		errors.ErrNotAllowed(rw, http.StatusUnauthorized)
+		return
+	}
+
+	var req models.APIKey
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		errors.ErrFailedUnmarshalJSON(rw, http.StatusBadRequest)
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		errors.ErrRequiredParam(rw, http.StatusBadRequest, "name")
+		return
+	}
+
+	validTier := false
+	for _, tier := range a.tiers {
+		if req.Tier == tier {
+			validTier = true
+		}
+	}
+
+	if !validTier {
+		errors.ErrInvalidParam(rw, http.StatusBadRequest, fmt.Sprintf("tier must be one of %s", strings.Join(a.tiers, ", ")))
+		return
+	}
+
+	key, err := utils.NewAPIKey()
+	if err != nil {
+		a.l.Error("failed to generate api key", "err", err)
+		errors.ErrInternalServer(rw, http.StatusInternalServerError)
+		return
+	}
+
+	row := &schema.APIKey{
+		KeyHash:   utils.HashAPIKey(key),
+		Name:      req.Name,
+		Tier:      req.Tier,
+		Timestamp: time.Now(),
+	}
+
+	err = a.db.WithContext(r.Context()).InsertAPIKey(row)
+	if err != nil {
+		a.l.Error("failed to insert api key", "err", err)
+		errors.ErrInternalServer(rw, http.StatusInternalServerError)
+		return
+	}
+
+	utils.Respond(rw, &models.APIKey{
+		ID:        row.ID,
+		Key:       key,
+		Name:      row.Name,
+		Tier:      row.Tier,
+		Timestamp: row.Timestamp,
+	})
+	return
+}
+
+// DeleteAPIKey disables an API key. Disabled keys are kept so that they can't be reused
+func (a *Admin) DeleteAPIKey(rw http.ResponseWriter, r *http.Request) {
+	if !a.authorized(r) {
+		errors.ErrNotAllowed(rw, http.StatusUnauthorized)
+		return
+	}
+
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		errors.ErrInvalidParam(rw, http.StatusBadRequest, "id is invalid")
+		return
+	}
+
+	ok, err := a.db.WithContext(r.Context()).DisableAPIKey(int32(id))
+	if err != nil {
+		a.l.Error("failed to disable api key", "err", err)
+		errors.ErrInternalServer(rw, http.StatusInternalServerError)
+		return
+	}
+
+	if !ok {
+		errors.ErrNotExist(rw, http.StatusNotFound)
+		return
+	}
+
+	rw.WriteHeader(http.StatusNoContent)
+	return
+}
+
+// parseLabelsCSV parses address labels in CSV format. A header row starting with "address" is skipped
+func parseLabelsCSV(r io.Reader) ([]models.Label, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse csv: %s", err)
+	}
+
+	labels := make([]models.Label, 0)
+	for i, record := range records {
+		if i == 0 && strings.EqualFold(strings.TrimSpace(record[0]), "address") {
+			continue
+		}
+
+		if len(record) < 2 {
+			return nil, fmt.Errorf("address and label are required at line %d", i+1)
+		}
+
+		label := models.Label{
+			Address: record[0],
+			Label:   record[1],
+		}
+
+		if len(record) > 2 {
+			label.Category = record[2]
+		}
+
+		labels = append(labels, label)
+	}
+
+	return labels, nil
+}
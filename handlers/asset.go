@@ -2,33 +2,35 @@ package handlers
 
 import (
 	"encoding/json"
-	"log"
 	"net/http"
 	"strconv"
+	"time"
 
 	"mintscan/client"
 	"mintscan/db"
 	"mintscan/errors"
 	"mintscan/models"
 	"mintscan/utils"
+
+	"github.com/gorilla/mux"
+
+	"github.com/tendermint/tendermint/libs/log"
 )
 
 // Asset is a asset handler
 type Asset struct {
-	l      *log.Logger
+	l      log.Logger
 	client *client.Client
 	db     *db.Database
 }
 
 // NewAsset creates a new asset handler with the given params
-func NewAsset(l *log.Logger, client *client.Client, db *db.Database) *Asset {
+func NewAsset(l log.Logger, client *client.Client, db *db.Database) *Asset {
 	return &Asset{l, client, db}
 }
 
 // GetAsset returns asset based upon the request params
 func (a *Asset) GetAsset(rw http.ResponseWriter, r *http.Request) {
-	rw.Header().Set("Access-Control-Allow-Origin", "*")
-
 	if len(r.URL.Query()["asset"]) <= 0 {
 		errors.ErrRequiredParam(rw, http.StatusBadRequest, "'asset' is not present")
 		return
@@ -36,9 +38,9 @@ func (a *Asset) GetAsset(rw http.ResponseWriter, r *http.Request) {
 
 	asset := r.URL.Query()["asset"][0]
 
-	result, err := a.client.Asset(asset)
+	result, err := a.client.WithContext(r.Context()).Asset(asset)
 	if err != nil {
-		a.l.Printf("failed to get asset detail information: %s\n", err)
+		a.l.Error("failed to get asset detail information", "err", err)
 	}
 
 	utils.Respond(rw, result)
@@ -47,7 +49,6 @@ func (a *Asset) GetAsset(rw http.ResponseWriter, r *http.Request) {
 
 // GetAssets returns assets based upon the request params
 func (a *Asset) GetAssets(rw http.ResponseWriter, r *http.Request) {
-	rw.Header().Set("Access-Control-Allow-Origin", "*")
 	onlyPrice := "false" // default is false, when true it only show assets price information
 
 	if len(r.URL.Query()["page"]) <= 0 {
@@ -77,9 +78,9 @@ func (a *Asset) GetAssets(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	assets, err := a.client.Assets(page, rows)
+	assets, err := a.client.WithContext(r.Context()).Assets(page, rows)
 	if err != nil {
-		a.l.Printf("failed to get asset list: %s\n", err)
+		a.l.Error("failed to get asset list", "err", err)
 	}
 
 	if onlyPrice == "true" {
@@ -112,8 +113,6 @@ func (a *Asset) GetAssets(rw http.ResponseWriter, r *http.Request) {
 
 // GetAssetHolders returns asset holders based upon the request params
 func (a *Asset) GetAssetHolders(rw http.ResponseWriter, r *http.Request) {
-	rw.Header().Set("Access-Control-Allow-Origin", "*")
-
 	if len(r.URL.Query()["asset"]) <= 0 {
 		errors.ErrRequiredParam(rw, http.StatusBadRequest, "'asset' is not present")
 		return
@@ -143,9 +142,21 @@ func (a *Asset) GetAssetHolders(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	result, err := a.client.AssetHolders(asset, page, rows)
+	result, err := a.client.WithContext(r.Context()).AssetHolders(asset, page, rows)
 	if err != nil {
-		a.l.Printf("failed to get asset holders list: %s\n", err)
+		a.l.Error("failed to get asset holders list", "err", err)
+	}
+
+	addresses := make([]string, 0)
+	for _, holder := range result.AddressHolders {
+		addresses = append(addresses, holder.Address)
+	}
+
+	labels := addressLabels(r.Context(), a.l, a.db, addresses...)
+	for i, holder := range result.AddressHolders {
+		if label, ok := labels[holder.Address]; ok {
+			result.AddressHolders[i].Tag = label
+		}
 	}
 
 	utils.Respond(rw, result)
@@ -154,8 +165,6 @@ func (a *Asset) GetAssetHolders(rw http.ResponseWriter, r *http.Request) {
 
 // GetAssetsImages returns images of all assets
 func (a *Asset) GetAssetsImages(rw http.ResponseWriter, r *http.Request) {
-	rw.Header().Set("Access-Control-Allow-Origin", "*")
-
 	if len(r.URL.Query()["page"]) <= 0 {
 		errors.ErrRequiredParam(rw, http.StatusBadRequest, "'page' is not present")
 		return
@@ -179,9 +188,9 @@ func (a *Asset) GetAssetsImages(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	assets, err := a.client.Assets(page, rows)
+	assets, err := a.client.WithContext(r.Context()).Assets(page, rows)
 	if err != nil {
-		a.l.Printf("failed to get asset list: %s\n", err)
+		a.l.Error("failed to get asset list", "err", err)
 	}
 
 	imageList := make([]models.ImageList, 0)
@@ -209,8 +218,6 @@ func (a *Asset) GetAssetsImages(rw http.ResponseWriter, r *http.Request) {
 
 // GetAssetTxs returns asset txs
 func (a *Asset) GetAssetTxs(rw http.ResponseWriter, r *http.Request) {
-	rw.Header().Set("Access-Control-Allow-Origin", "*")
-
 	if len(r.URL.Query()["txAsset"]) <= 0 {
 		errors.ErrRequiredParam(rw, http.StatusBadRequest, "'txAsset' is not present")
 		return
@@ -240,9 +247,9 @@ func (a *Asset) GetAssetTxs(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	assetTxs, err := a.client.AssetTxs(txAsset, page, rows)
+	assetTxs, err := a.client.WithContext(r.Context()).AssetTxs(txAsset, page, rows)
 	if err != nil {
-		a.l.Printf("failed to get asset list: %s\n", err)
+		a.l.Error("failed to get asset list", "err", err)
 	}
 
 	txArray := make([]models.AssetTxArray, 0)
@@ -277,7 +284,7 @@ func (a *Asset) GetAssetTxs(rw http.ResponseWriter, r *http.Request) {
 		if tx.Data != "" {
 			err = json.Unmarshal([]byte(tx.Data), &data)
 			if err != nil {
-				a.l.Printf("failed to unmarshal AssetTxData: %s", err)
+				a.l.Error("failed to unmarshal AssetTxData", "err", err)
 			}
 
 			tempTxArray.Message = &data
@@ -286,6 +293,17 @@ func (a *Asset) GetAssetTxs(rw http.ResponseWriter, r *http.Request) {
 		txArray = append(txArray, *tempTxArray)
 	}
 
+	addresses := make([]string, 0)
+	for _, tx := range txArray {
+		addresses = append(addresses, tx.FromAddr, tx.ToAddr)
+	}
+
+	labels := addressLabels(r.Context(), a.l, a.db, addresses...)
+	for i := range txArray {
+		txArray[i].FromAddrLabel = labels[txArray[i].FromAddr]
+		txArray[i].ToAddrLabel = labels[txArray[i].ToAddr]
+	}
+
 	result := &models.ResultAssetTxs{
 		TxNums:  assetTxs.TxNums,
 		TxArray: txArray,
@@ -294,3 +312,200 @@ func (a *Asset) GetAssetTxs(rw http.ResponseWriter, r *http.Request) {
 	utils.Respond(rw, result)
 	return
 }
+
+// untrackedHolderPages is max number of asset holder pages of 100 holders requested per request
+// for distribution of an asset that snapshots are not taken for
+const untrackedHolderPages = 10
+
+// GetAssetDistribution returns holder concentration and distribution of an asset from the latest snapshot
+// with holder count changes computed from periodic snapshots. Snapshots are taken by exporter for featured
+// assets and assets that distribution is configured to be tracked for, and distribution of other assets
+// is computed on request
+func (a *Asset) GetAssetDistribution(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	asset := vars["asset"]
+
+	if asset == "" {
+		errors.ErrRequiredParam(rw, http.StatusBadRequest, "asset is required")
+		return
+	}
+
+	now := time.Now().UTC()
+
+	snapshots, err := a.db.WithContext(r.Context()).QueryAssetHolderSnapshots(asset, now.AddDate(0, 0, -30))
+	if err != nil {
+		a.l.Error("failed to query asset holder snapshots", "err", err)
+		errors.ErrInternalServer(rw, http.StatusInternalServerError)
+		return
+	}
+
+	// Distribution of an asset that is not tracked is computed from fewer top holders without history
+	if len(snapshots) <= 0 {
+		result, err := a.client.WithContext(r.Context()).AssetDistribution(asset, untrackedHolderPages)
+		if err != nil {
+			a.l.Error("failed to get asset distribution", "asset", asset, "err", err)
+			errors.ErrInternalServer(rw, http.StatusInternalServerError)
+			return
+		}
+
+		if result.Holders <= 0 {
+			errors.ErrNotExist(rw, http.StatusNotFound)
+			return
+		}
+
+		result.UpdatedAt = now
+
+		utils.Respond(rw, result)
+		return
+	}
+
+	latest := snapshots[len(snapshots)-1]
+
+	result := models.AssetDistribution{
+		Asset:          asset,
+		Holders:        latest.Holders,
+		SampledHolders: latest.SampledHolders,
+		Partial:        latest.SampledHolders < latest.Holders,
+		Gini:           latest.Gini,
+		Top10Share:     latest.Top10Share,
+		Top100Share:    latest.Top100Share,
+		Buckets:        make([]models.HolderBucket, 0),
+		History:        make([]models.HolderSnapshot, 0),
+		UpdatedAt:      latest.Timestamp,
+	}
+
+	err = json.Unmarshal([]byte(latest.Buckets), &result.Buckets)
+	if err != nil {
+		a.l.Error("failed to unmarshal holder buckets", "err", err)
+	}
+
+	var holders24H, holders7D int
+	for _, snapshot := range snapshots {
+		if !snapshot.Timestamp.After(now.Add(-24 * time.Hour)) {
+			holders24H = snapshot.Holders
+		}
+
+		if !snapshot.Timestamp.After(now.AddDate(0, 0, -7)) {
+			holders7D = snapshot.Holders
+		}
+
+		result.History = append(result.History, models.HolderSnapshot{
+			Holders:     snapshot.Holders,
+			Gini:        snapshot.Gini,
+			Top10Share:  snapshot.Top10Share,
+			Top100Share: snapshot.Top100Share,
+			Timestamp:   snapshot.Timestamp,
+		})
+	}
+
+	if holders24H > 0 {
+		result.HoldersChange24H = result.Holders - holders24H
+	}
+
+	if holders7D > 0 {
+		result.HoldersChange7D = result.Holders - holders7D
+	}
+
+	utils.Respond(rw, result)
+	return
+}
+
+// GetAssetSupplyEvents returns supply-changing events of an asset with running totals, which are saved
+// by exporter, and reconciles the running total supply against total supply reported by API server
+func (a *Asset) GetAssetSupplyEvents(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	asset := vars["asset"]
+
+	if asset == "" {
+		errors.ErrRequiredParam(rw, http.StatusBadRequest, "asset is required")
+		return
+	}
+
+	before := int(0)
+	limit := int(50)
+
+	if len(r.URL.Query()["before"]) > 0 {
+		before, _ = strconv.Atoi(r.URL.Query()["before"][0])
+	}
+
+	if len(r.URL.Query()["limit"]) > 0 {
+		limit, _ = strconv.Atoi(r.URL.Query()["limit"][0])
+	}
+
+	if limit < 1 {
+		errors.ErrInvalidParam(rw, http.StatusBadRequest, "'limit' cannot be less than 1")
+		return
+	}
+
+	if limit > 100 {
+		errors.ErrOverMaxLimit(rw, http.StatusUnauthorized)
+		return
+	}
+
+	events, err := a.db.WithContext(r.Context()).QuerySupplyEvents(asset, before, limit)
+	if err != nil {
+		a.l.Error("failed to query supply events", "err", err)
+		errors.ErrInternalServer(rw, http.StatusInternalServerError)
+		return
+	}
+
+	latest, err := a.db.WithContext(r.Context()).QueryLatestSupplyEvent(asset)
+	if err != nil {
+		a.l.Error("failed to query latest supply event", "err", err)
+		errors.ErrInternalServer(rw, http.StatusInternalServerError)
+		return
+	}
+
+	addresses := make([]string, 0)
+	for _, event := range events {
+		addresses = append(addresses, event.From)
+	}
+
+	labels := addressLabels(r.Context(), a.l, a.db, addresses...)
+
+	// Amounts are summed in base units by exporter and converted only for display
+	result := &models.ResultSupplyEvents{
+		Asset:       asset,
+		TotalSupply: float64(latest.TotalSupply) / 1e8,
+		Events:      make([]models.SupplyEvent, 0),
+	}
+
+	for _, event := range events {
+		result.Events = append(result.Events, models.SupplyEvent{
+			ID:          event.ID,
+			Type:        event.Type,
+			Height:      event.Height,
+			TxHash:      event.TxHash,
+			From:        event.From,
+			FromLabel:   labels[event.From],
+			Amount:      float64(event.Amount) / 1e8,
+			TotalSupply: float64(event.TotalSupply) / 1e8,
+			Frozen:      float64(event.Frozen) / 1e8,
+			Locked:      float64(event.Locked) / 1e8,
+			Timestamp:   event.Timestamp,
+		})
+	}
+
+	if len(result.Events) > 0 {
+		result.Paging.Total = int32(len(result.Events))
+		result.Paging.Before = result.Events[len(result.Events)-1].ID
+		result.Paging.After = result.Events[0].ID
+	}
+
+	token, err := a.client.WithContext(r.Context()).Token(asset)
+	if err != nil {
+		a.l.Error("failed to get token information", "err", err)
+	} else {
+		tokenTotalSupply, err := utils.ParseBaseUnits(token.TotalSupply)
+		if err != nil {
+			a.l.Error("failed to parse token total supply", "err", err)
+		} else {
+			result.TokenTotalSupply = float64(tokenTotalSupply) / 1e8
+			result.Difference = float64(tokenTotalSupply-latest.TotalSupply) / 1e8
+			result.Reconciled = tokenTotalSupply == latest.TotalSupply
+		}
+	}
+
+	utils.Respond(rw, result)
+	return
+}
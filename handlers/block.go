@@ -1,34 +1,46 @@
 package handlers
 
 import (
+	"context"
+	"encoding/hex"
 	"encoding/json"
-	"log"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"mintscan/client"
+	"mintscan/codec"
 	"mintscan/db"
 	"mintscan/errors"
 	"mintscan/models"
 	"mintscan/schema"
 	"mintscan/utils"
+
+	"github.com/gorilla/mux"
+
+	"github.com/binance-chain/go-sdk/common/bech32"
+	cmtypes "github.com/binance-chain/go-sdk/common/types"
+	"github.com/binance-chain/go-sdk/types/tx"
+
+	"github.com/tendermint/tendermint/libs/log"
 )
 
 // Block is a block handler
 type Block struct {
-	l      *log.Logger
+	l      log.Logger
 	client *client.Client
 	db     *db.Database
+	nt     cmtypes.ChainNetwork
 }
 
 // NewBlock creates a new block handler with the given params
-func NewBlock(l *log.Logger, client *client.Client, db *db.Database) *Block {
-	return &Block{l, client, db}
+func NewBlock(l log.Logger, client *client.Client, db *db.Database, nt cmtypes.ChainNetwork) *Block {
+	return &Block{l, client, db, nt}
 }
 
 // GetBlocks returns blocks based upon the request params
 func (b *Block) GetBlocks(rw http.ResponseWriter, r *http.Request) {
-	rw.Header().Set("Access-Control-Allow-Origin", "*")
 	before := int(0)
 	after := int(-1)
 	limit := int(100)
@@ -50,22 +62,24 @@ func (b *Block) GetBlocks(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	blocks, err := b.client.Blocks(before, after, limit)
+	blocks, err := b.client.WithContext(r.Context()).Blocks(before, after, limit)
 	if err != nil {
-		b.l.Printf("failed to query blocks: %s\n", err)
+		b.l.Error("failed to query blocks", "err", err)
 	}
 
 	if len(blocks) <= 0 {
 		return
 	}
 
+	b.setProposerLabels(r.Context(), blocks)
+
 	result := &models.ResultBlocks{
 		Data: blocks,
 	}
 
-	latestBlockHeight, err := b.client.LastBlockHeight()
+	latestBlockHeight, err := b.client.WithContext(r.Context()).LastBlockHeight()
 	if err != nil {
-		b.l.Printf("failed to query latest block height: %s\n", err)
+		b.l.Error("failed to query latest block height", "err", err)
 	}
 
 	// Handling before and after since their ordering data is different
@@ -83,8 +97,219 @@ func (b *Block) GetBlocks(rw http.ResponseWriter, r *http.Request) {
 	return
 }
 
+// GetBlock returns a block with its transactions and signers given a block height.
+// Blocks that are not indexed yet are requested from the node
+func (b *Block) GetBlock(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	height, err := strconv.ParseInt(vars["height"], 10, 64)
+	if err != nil || height < 1 {
+		errors.ErrInvalidParam(rw, http.StatusBadRequest, "height is invalid")
+		return
+	}
+
+	var data models.BlockData
+
+	block, err := b.db.WithContext(r.Context()).QueryBlockByHeight(height)
+	if err == nil {
+		result, _ := b.setBlocks(r.Context(), []schema.Block{block})
+		data = result.Data[0]
+	} else {
+		data, err = b.nodeBlock(r.Context(), height)
+		if err != nil {
+			b.l.Error("failed to request block", "err", err)
+			errors.ErrNotExist(rw, http.StatusNotFound)
+			return
+		}
+
+		blocks := []models.BlockData{data}
+		b.setProposerLabels(r.Context(), blocks)
+		data = blocks[0]
+	}
+
+	b.setSigners(r.Context(), &data)
+
+	utils.Respond(rw, data)
+	return
+}
+
+// GetBlockByHash returns an indexed block with its transactions and signers given a block hash
+func (b *Block) GetBlockByHash(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	hash := strings.ToUpper(vars["hash"])
+
+	if len(hash) != 64 {
+		errors.ErrInvalidParam(rw, http.StatusBadRequest, "hash is invalid")
+		return
+	}
+
+	block, err := b.db.WithContext(r.Context()).QueryBlockByHash(hash)
+	if err != nil {
+		b.l.Error("failed to query block by hash", "err", err)
+		errors.ErrNotExist(rw, http.StatusNotFound)
+		return
+	}
+
+	result, _ := b.setBlocks(r.Context(), []schema.Block{block})
+	data := result.Data[0]
+
+	b.setSigners(r.Context(), &data)
+
+	utils.Respond(rw, data)
+	return
+}
+
+// nodeBlock requests a block that is not indexed yet from the node and decodes its transactions
+func (b *Block) nodeBlock(ctx context.Context, height int64) (models.BlockData, error) {
+	block, err := b.client.WithContext(ctx).Block(height)
+	if err != nil {
+		return models.BlockData{}, err
+	}
+
+	header := block.Block.Header
+
+	data := models.BlockData{
+		Height:     header.Height,
+		Proposer:   header.ProposerAddress.String(),
+		BlockHash:  block.BlockMeta.BlockID.Hash.String(),
+		ParentHash: header.LastBlockID.Hash.String(),
+		NumTxs:     header.NumTxs,
+		TotalTxs:   header.TotalTxs,
+		Txs:        make([]models.Txs, 0),
+		Timestamp:  header.Time,
+	}
+
+	if len(block.Block.Data.Txs) <= 0 {
+		return data, nil
+	}
+
+	results, err := b.client.WithContext(ctx).BlockResults(height)
+	if err != nil {
+		b.l.Error("failed to request block results", "err", err)
+	}
+
+	for i, bz := range block.Block.Data.Txs {
+		var stdTx tx.StdTx
+		err := codec.Codec.UnmarshalBinaryLengthPrefixed(bz, &stdTx)
+		if err != nil {
+			return data, fmt.Errorf("failed to decode tx: %s", err)
+		}
+
+		msgs := make([]models.Message, 0)
+		for _, msg := range stdTx.GetMsgs() {
+			var m models.Message
+			msgBz, err := codec.Codec.MarshalJSON(msg)
+			if err == nil {
+				msgBz, err = utils.EncodeAccAddresses(b.nt, msgBz)
+			}
+			if err == nil {
+				err = json.Unmarshal(msgBz, &m)
+			}
+			if err != nil {
+				return data, fmt.Errorf("failed to encode msg: %s", err)
+			}
+
+			msgs = append(msgs, m)
+		}
+
+		resultTx := models.Txs{
+			Height:    header.Height,
+			Result:    true,
+			TxHash:    strings.ToUpper(hex.EncodeToString(bz.Hash())),
+			Messages:  msgs,
+			Memo:      stdTx.Memo,
+			Timestamp: header.Time,
+		}
+
+		if results != nil && results.Results != nil && i < len(results.Results.DeliverTx) {
+			resultTx.Code = results.Results.DeliverTx[i].Code
+			resultTx.Result = resultTx.Code == 0
+		}
+
+		data.Txs = append(data.Txs, resultTx)
+	}
+
+	return data, nil
+}
+
+// setSigners sets the validator set of a block with whether each validator signed the block,
+// and resolves the proposer to its moniker. Precommits that are not indexed yet are taken
+// from the last commit of the next block
+func (b *Block) setSigners(ctx context.Context, data *models.BlockData) {
+	validators := b.consensusValidators(ctx)
+
+	if data.Moniker == "" {
+		data.Moniker = validators[consensusAddressKey(data.Proposer)].Moniker
+	}
+
+	signed := make(map[string]bool)
+
+	precommits, err := b.db.WithContext(ctx).QueryPreCommits(data.Height)
+	if err != nil {
+		b.l.Error("failed to query precommits", "err", err)
+	}
+
+	for _, precommit := range precommits {
+		signed[consensusAddressKey(precommit.ValidatorAddress)] = true
+	}
+
+	if len(precommits) <= 0 {
+		next, err := b.client.WithContext(ctx).Block(data.Height + 1)
+		if err == nil && next.Block.LastCommit != nil {
+			for _, precommit := range next.Block.LastCommit.Precommits {
+				if precommit != nil {
+					signed[consensusAddressKey(precommit.ValidatorAddress.String())] = true
+				}
+			}
+		}
+	}
+
+	vals, err := b.client.WithContext(ctx).ValidatorSet(data.Height)
+	if err != nil {
+		b.l.Error("failed to request validator set", "err", err)
+		return
+	}
+
+	data.Signers = make([]models.Signer, 0)
+	for _, val := range vals.Validators {
+		key := consensusAddressKey(val.Address.String())
+
+		data.Signers = append(data.Signers, models.Signer{
+			Address:     val.Address.String(),
+			Moniker:     validators[key].Moniker,
+			VotingPower: val.VotingPower,
+			Signed:      signed[key],
+		})
+	}
+}
+
+// consensusValidators returns validators keyed by consensus address
+func (b *Block) consensusValidators(ctx context.Context) map[string]schema.Validator {
+	validators := make(map[string]schema.Validator)
+
+	vals, err := b.db.WithContext(ctx).QueryValidators()
+	if err != nil {
+		b.l.Error("failed to query validators", "err", err)
+	}
+
+	for _, val := range vals {
+		validators[consensusAddressKey(val.ConsensusAddress)] = *val
+	}
+
+	return validators
+}
+
+// consensusAddressKey normalizes a consensus address in either bech32 or hex format to upper case hex
+func consensusAddressKey(address string) string {
+	if _, bz, err := bech32.DecodeAndConvert(address); err == nil {
+		return strings.ToUpper(hex.EncodeToString(bz))
+	}
+
+	return strings.ToUpper(address)
+}
+
 // setBlocks handles blocks and return result response
-func (b *Block) setBlocks(blocks []schema.Block) (*models.ResultBlocks, error) {
+func (b *Block) setBlocks(ctx context.Context, blocks []schema.Block) (*models.ResultBlocks, error) {
 
 	data := make([]models.BlockData, 0)
 
@@ -93,12 +318,12 @@ func (b *Block) setBlocks(blocks []schema.Block) (*models.ResultBlocks, error) {
 
 		// Check if any transaction exists in this block
 		if block.NumTxs > 0 {
-			txs, _ := b.db.QueryTx(block.Height)
+			txs, _ := b.db.WithContext(ctx).QueryTx(block.Height)
 			for _, tx := range txs {
 				msgs := make([]models.Message, 0)
 				err := json.Unmarshal([]byte(tx.Messages), &msgs)
 				if err != nil {
-					b.l.Printf("failed to unmarshal msgs: %s\n", err)
+					b.l.Error("failed to unmarshal msgs", "err", err)
 				}
 
 				txResult := true
@@ -136,9 +361,33 @@ func (b *Block) setBlocks(blocks []schema.Block) (*models.ResultBlocks, error) {
 		data = append(data, *tempData)
 	}
 
+	b.setProposerLabels(ctx, data)
+
 	result := &models.ResultBlocks{
 		Data: data,
 	}
 
 	return result, nil
 }
+
+// setProposerLabels attaches labels of block proposers. Proposers are resolved from their
+// consensus addresses to operator addresses, falling back to account addresses
+func (b *Block) setProposerLabels(ctx context.Context, blocks []models.BlockData) {
+	validators := b.consensusValidators(ctx)
+
+	addresses := make([]string, 0)
+	for _, block := range blocks {
+		val := validators[consensusAddressKey(block.Proposer)]
+		addresses = append(addresses, val.OperatorAddress, val.AccountAddress)
+	}
+
+	labels := addressLabels(ctx, b.l, b.db, addresses...)
+	for i := range blocks {
+		val := validators[consensusAddressKey(blocks[i].Proposer)]
+
+		blocks[i].ProposerLabel = labels[val.OperatorAddress]
+		if blocks[i].ProposerLabel == "" {
+			blocks[i].ProposerLabel = labels[val.AccountAddress]
+		}
+	}
+}
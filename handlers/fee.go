@@ -1,32 +1,32 @@
 package handlers
 
 import (
-	"log"
 	"net/http"
 
 	"mintscan/client"
 	"mintscan/db"
 	"mintscan/utils"
+
+	"github.com/tendermint/tendermint/libs/log"
 )
 
 // Fee is a fee handler
 type Fee struct {
-	l      *log.Logger
+	l      log.Logger
 	client *client.Client
 	db     *db.Database
 }
 
 // NewFee creates a new fee handler with the given params
-func NewFee(l *log.Logger, client *client.Client, db *db.Database) *Fee {
+func NewFee(l log.Logger, client *client.Client, db *db.Database) *Fee {
 	return &Fee{l, client, db}
 }
 
 // GetFees returns current fee on the active chain
 func (f *Fee) GetFees(rw http.ResponseWriter, r *http.Request) {
-	rw.Header().Set("Access-Control-Allow-Origin", "*")
-	fees, err := f.client.TxMsgFees()
+	fees, err := f.client.WithContext(r.Context()).TxMsgFees()
 	if err != nil {
-		f.l.Printf("failed to fetch tx msg fees: %s", err)
+		f.l.Error("failed to fetch tx msg fees", "err", err)
 		return
 	}
 
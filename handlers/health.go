@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"mintscan/client"
+	"mintscan/db"
+	"mintscan/models"
+	"mintscan/utils"
+
+	"github.com/tendermint/tendermint/libs/log"
+)
+
+// healthCheckTimeout bounds each dependency check so that a hanging dependency fails readiness
+// rather than the probe itself
+const healthCheckTimeout = 5 * time.Second
+
+// upstreamCheckTTL is how long results of upstream API checks are reused, so that third-party
+// APIs are not requested on every probe
+const upstreamCheckTTL = 30 * time.Second
+
+// HealthTarget is a network whose dependencies are checked for readiness
+type HealthTarget struct {
+	Network         string
+	Client          *client.Client
+	DB              *db.Database
+	StaleAfter      time.Duration // max age of the latest indexed block
+	Optional        []string      // checks that don't fail readiness
+	OptionalNetwork bool          // the network doesn't fail readiness
+}
+
+// Health is a health handler that checks dependencies of every served network
+type Health struct {
+	l       log.Logger
+	targets []HealthTarget
+
+	mu        sync.Mutex
+	upstreams map[string]cachedCheck // results of upstream checks keyed by network and upstream name
+}
+
+// cachedCheck is a dependency check result with the time it was checked
+type cachedCheck struct {
+	check models.DependencyCheck
+	time  time.Time
+}
+
+// NewHealth creates a new health handler with the given params
+func NewHealth(l log.Logger, targets []HealthTarget) *Health {
+	return &Health{l: l, targets: targets, upstreams: make(map[string]cachedCheck)}
+}
+
+// GetHealthz returns ok as long as the process is able to serve requests
+func (h *Health) GetHealthz(rw http.ResponseWriter, r *http.Request) {
+	rw.Header().Set("Cache-Control", "no-store")
+
+	utils.Respond(rw, &models.ResultHealth{
+		Status: models.HealthStatusOK,
+	})
+	return
+}
+
+// GetReadyz checks database, node, upstream APIs and indexer freshness of every network.
+// It responds 503 when any check that is not optional fails in a network that is not optional
+func (h *Health) GetReadyz(rw http.ResponseWriter, r *http.Request) {
+	rw.Header().Set("Cache-Control", "no-store")
+
+	result := &models.ResultHealth{
+		Status:   models.HealthStatusOK,
+		Networks: make(map[string]models.NetworkHealth),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for _, target := range h.targets {
+		wg.Add(1)
+		go func(target HealthTarget) {
+			defer wg.Done()
+
+			network := h.checkNetwork(r.Context(), target)
+
+			mu.Lock()
+			result.Networks[target.Network] = network
+			if network.Status != models.HealthStatusOK && !network.Optional {
+				result.Status = models.HealthStatusUnavailable
+			}
+			mu.Unlock()
+		}(target)
+	}
+
+	wg.Wait()
+
+	if result.Status != models.HealthStatusOK {
+		utils.RespondWithStatus(rw, http.StatusServiceUnavailable, result)
+		return
+	}
+
+	utils.Respond(rw, result)
+	return
+}
+
+// checkNetwork runs every dependency check of a network concurrently
+func (h *Health) checkNetwork(ctx context.Context, target HealthTarget) models.NetworkHealth {
+	checks := map[string]func(context.Context) (string, error){
+		"database": func(ctx context.Context) (string, error) {
+			return "", target.DB.WithContext(ctx).Ping()
+		},
+		"node": func(ctx context.Context) (string, error) {
+			status, err := target.Client.WithContext(ctx).Status()
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("latest block %d", status.SyncInfo.LatestBlockHeight), nil
+		},
+		"indexer": func(ctx context.Context) (string, error) {
+			block, err := target.DB.WithContext(ctx).QueryLatestBlock()
+			if err != nil {
+				return "", err
+			}
+
+			age := time.Since(block.Timestamp).Round(time.Second)
+			detail := fmt.Sprintf("latest indexed block %d is %s old", block.Height, age)
+			if age > target.StaleAfter {
+				return detail, fmt.Errorf("indexer is stale, threshold is %s", target.StaleAfter)
+			}
+			return detail, nil
+		},
+	}
+
+	upstreams := make(map[string]bool)
+	for _, name := range target.Client.UpstreamNames() {
+		name := name
+		upstreams[name] = true
+		checks[name] = func(ctx context.Context) (string, error) {
+			return "", target.Client.WithContext(ctx).PingUpstream(name)
+		}
+	}
+
+	optional := make(map[string]bool)
+	for _, name := range target.Optional {
+		optional[name] = true
+	}
+
+	result := models.NetworkHealth{
+		Status:   models.HealthStatusOK,
+		Optional: target.OptionalNetwork,
+		Checks:   make(map[string]models.DependencyCheck),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check func(context.Context) (string, error)) {
+			defer wg.Done()
+
+			var dc models.DependencyCheck
+			if upstreams[name] {
+				dc = h.upstreamCheck(ctx, target.Network+"/"+name, check)
+			} else {
+				dc = runHealthCheck(ctx, check)
+			}
+			dc.Optional = optional[name]
+
+			if dc.Status != models.HealthStatusOK && !dc.Cached {
+				h.l.Error("dependency check failed", "network", target.Network, "check", name, "err", dc.Error)
+			}
+
+			mu.Lock()
+			result.Checks[name] = dc
+			if dc.Status != models.HealthStatusOK && !dc.Optional {
+				result.Status = models.HealthStatusUnavailable
+			}
+			mu.Unlock()
+		}(name, check)
+	}
+
+	wg.Wait()
+
+	return result
+}
+
+// upstreamCheck returns the result of an upstream check that is checked within upstreamCheckTTL,
+// and runs the check otherwise
+func (h *Health) upstreamCheck(ctx context.Context, key string, check func(context.Context) (string, error)) models.DependencyCheck {
+	h.mu.Lock()
+	cached, ok := h.upstreams[key]
+	h.mu.Unlock()
+
+	if ok && time.Since(cached.time) < upstreamCheckTTL {
+		dc := cached.check
+		dc.Cached = true
+		return dc
+	}
+
+	dc := runHealthCheck(ctx, check)
+
+	// Checks that are cancelled by the probe say nothing about the upstream
+	if ctx.Err() == nil {
+		h.mu.Lock()
+		h.upstreams[key] = cachedCheck{dc, time.Now()}
+		h.mu.Unlock()
+	}
+
+	return dc
+}
+
+// runHealthCheck runs a dependency check that returns detail of the dependency with a context
+// that is cancelled after healthCheckTimeout, failing the check when it doesn't finish in time
+func runHealthCheck(ctx context.Context, check func(context.Context) (string, error)) models.DependencyCheck {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+	detail, err := check(ctx)
+	if ctx.Err() == context.DeadlineExceeded {
+		err = fmt.Errorf("timed out after %s", healthCheckTimeout)
+	}
+
+	result := models.DependencyCheck{
+		Status:    models.HealthStatusOK,
+		LatencyMs: time.Since(start).Milliseconds(),
+		Detail:    detail,
+	}
+
+	if err != nil {
+		result.Status = models.HealthStatusUnavailable
+		result.Error = err.Error()
+	}
+
+	return result
+}
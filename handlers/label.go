@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+
+	"mintscan/db"
+	"mintscan/models"
+	"mintscan/utils"
+
+	cmtypes "github.com/binance-chain/go-sdk/common/types"
+	"github.com/tendermint/tendermint/libs/log"
+)
+
+// addressLabels queries labels of the given addresses and returns them keyed by address.
+// Empty and duplicate addresses are skipped, and failing to query labels doesn't fail the response
+func addressLabels(ctx context.Context, l log.Logger, db *db.Database, addresses ...string) map[string]string {
+	result := make(map[string]string)
+
+	seen := make(map[string]bool)
+	unique := make([]string, 0)
+	for _, address := range addresses {
+		if address == "" || seen[address] {
+			continue
+		}
+		seen[address] = true
+		unique = append(unique, address)
+	}
+
+	labels, err := db.WithContext(ctx).QueryLabels(unique)
+	if err != nil {
+		l.Error("failed to query labels", "err", err)
+		return result
+	}
+
+	for _, label := range labels {
+		result[label.Address] = label.Label
+	}
+
+	return result
+}
+
+// setTxLabels attaches labels of signers and of every address that appears in messages of txs.
+// Labels of all txs are queried at once
+func setTxLabels(ctx context.Context, l log.Logger, db *db.Database, nt cmtypes.ChainNetwork, txs []models.TxData) {
+	txAddresses := make([][]string, len(txs))
+	all := make([]string, 0)
+
+	for i, tx := range txs {
+		for _, sig := range tx.Signatures {
+			txAddresses[i] = append(txAddresses[i], sig.Address)
+		}
+
+		for _, msg := range tx.Messages {
+			var value interface{}
+			if json.Unmarshal(msg.Value, &value) != nil {
+				continue
+			}
+			txAddresses[i] = msgAddresses(nt, value, txAddresses[i])
+		}
+
+		all = append(all, txAddresses[i]...)
+	}
+
+	labels := addressLabels(ctx, l, db, all...)
+	if len(labels) <= 0 {
+		return
+	}
+
+	for i := range txs {
+		for _, address := range txAddresses[i] {
+			if label, ok := labels[address]; ok {
+				if txs[i].Labels == nil {
+					txs[i].Labels = make(map[string]string)
+				}
+				txs[i].Labels[address] = label
+			}
+		}
+	}
+}
+
+// msgAddresses appends every account and operator address found in a decoded message value
+func msgAddresses(nt cmtypes.ChainNetwork, value interface{}, addresses []string) []string {
+	switch v := value.(type) {
+	case string:
+		if utils.IsAccAddress(nt, v) || utils.IsValAddress(nt, v) {
+			addresses = append(addresses, v)
+		}
+	case []interface{}:
+		for _, item := range v {
+			addresses = msgAddresses(nt, item, addresses)
+		}
+	case map[string]interface{}:
+		for _, item := range v {
+			addresses = msgAddresses(nt, item, addresses)
+		}
+	}
+
+	return addresses
+}
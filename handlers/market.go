@@ -2,7 +2,6 @@ package handlers
 
 import (
 	"fmt"
-	"log"
 	"net/http"
 	"time"
 
@@ -11,23 +10,24 @@ import (
 	"mintscan/errors"
 	"mintscan/models"
 	"mintscan/utils"
+
+	"github.com/tendermint/tendermint/libs/log"
 )
 
 // Market is a market handler
 type Market struct {
-	l      *log.Logger
+	l      log.Logger
 	client *client.Client
 	db     *db.Database
 }
 
 // NewMarket creates a new market handler with the given params
-func NewMarket(l *log.Logger, client *client.Client, db *db.Database) *Market {
+func NewMarket(l log.Logger, client *client.Client, db *db.Database) *Market {
 	return &Market{l, client, db}
 }
 
 // GetCoinMarketData returns market data from CoinGecko API
 func (m *Market) GetCoinMarketData(rw http.ResponseWriter, r *http.Request) {
-	rw.Header().Set("Access-Control-Allow-Origin", "*")
 	if len(r.URL.Query()["id"]) <= 0 {
 		errors.ErrRequiredParam(rw, http.StatusBadRequest, "'id' is not present")
 		return
@@ -35,9 +35,9 @@ func (m *Market) GetCoinMarketData(rw http.ResponseWriter, r *http.Request) {
 
 	id := r.URL.Query()["id"][0]
 
-	data, err := m.client.CoinMarketData(id)
+	data, err := m.client.WithContext(r.Context()).CoinMarketData(id)
 	if err != nil {
-		m.l.Printf("failed to fetch coin market data: %s\n", err)
+		m.l.Error("failed to fetch coin market data", "err", err)
 	}
 
 	marketData := &models.Market{
@@ -62,7 +62,6 @@ func (m *Market) GetCoinMarketData(rw http.ResponseWriter, r *http.Request) {
 
 // GetCoinMarketChartData returns market chart data from CoinGecko API
 func (m *Market) GetCoinMarketChartData(rw http.ResponseWriter, r *http.Request) {
-	rw.Header().Set("Access-Control-Allow-Origin", "*")
 	if len(r.URL.Query()["id"]) <= 0 {
 		errors.ErrRequiredParam(rw, http.StatusBadRequest, "'id' is not present")
 		return
@@ -74,9 +73,9 @@ func (m *Market) GetCoinMarketChartData(rw http.ResponseWriter, r *http.Request)
 	to := time.Now().UTC()
 	from := to.AddDate(0, 0, -1)
 
-	marketChartData, err := m.client.CoinMarketChartData(id, fmt.Sprintf("%d", from.Unix()), fmt.Sprintf("%d", to.Unix()))
+	marketChartData, err := m.client.WithContext(r.Context()).CoinMarketChartData(id, fmt.Sprintf("%d", from.Unix()), fmt.Sprintf("%d", to.Unix()))
 	if err != nil {
-		m.l.Printf("failed to fetch coin market chart data: %s\n", err)
+		m.l.Error("failed to fetch coin market chart data", "err", err)
 	}
 
 	utils.Respond(rw, marketChartData)
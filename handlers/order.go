@@ -1,32 +1,46 @@
 package handlers
 
 import (
-	"log"
+	"context"
+	"encoding/json"
+	"fmt"
 	"net/http"
+	"strconv"
+	"sync"
 
 	"mintscan/client"
 	"mintscan/db"
 	"mintscan/errors"
+	"mintscan/models"
+	"mintscan/schema"
 	"mintscan/utils"
 
 	"github.com/gorilla/mux"
+
+	cmtypes "github.com/binance-chain/go-sdk/common/types"
+
+	"github.com/tendermint/tendermint/libs/log"
 )
 
+// maxOrderScanTxs bounds the number of new order txs scanned for a page filtered by status,
+// since status of every scanned order is requested from accelerated node
+const maxOrderScanTxs = 1000
+
 // Order is a order handler
 type Order struct {
-	l      *log.Logger
+	l      log.Logger
 	client *client.Client
 	db     *db.Database
+	nt     cmtypes.ChainNetwork
 }
 
 // NewOrder creates a new order handler with the given params
-func NewOrder(l *log.Logger, client *client.Client, db *db.Database) *Order {
-	return &Order{l, client, db}
+func NewOrder(l log.Logger, client *client.Client, db *db.Database, network cmtypes.ChainNetwork) *Order {
+	return &Order{l, client, db, network}
 }
 
 // GetOrders returns order information based up on order id
 func (o *Order) GetOrders(rw http.ResponseWriter, r *http.Request) {
-	rw.Header().Set("Access-Control-Allow-Origin", "*")
 	vars := mux.Vars(r)
 	id := vars["id"]
 
@@ -35,11 +49,306 @@ func (o *Order) GetOrders(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	order, err := o.client.Order(id)
+	order, err := o.client.WithContext(r.Context()).Order(id)
 	if err != nil {
-		o.l.Printf("failed to request order information: %s\n", err)
+		o.l.Error("failed to request order information", "err", err)
 	}
 
 	utils.Respond(rw, order)
 	return
 }
+
+// GetAccountOrders returns orders placed by an account with their current status
+func (o *Order) GetAccountOrders(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	address := vars["address"]
+
+	if address == "" {
+		errors.ErrRequiredParam(rw, http.StatusBadRequest, "address is required")
+		return
+	}
+
+	if !utils.IsAccAddress(o.nt, address) {
+		errors.ErrInvalidParam(rw, http.StatusBadRequest, "address is invalid")
+		return
+	}
+
+	status := ""
+	symbol := ""
+	before := int(0)
+	limit := int(50)
+
+	if len(r.URL.Query()["status"]) > 0 {
+		status = r.URL.Query()["status"][0]
+	}
+
+	if len(r.URL.Query()["symbol"]) > 0 {
+		symbol = r.URL.Query()["symbol"][0]
+	}
+
+	if len(r.URL.Query()["before"]) > 0 {
+		before, _ = strconv.Atoi(r.URL.Query()["before"][0])
+	}
+
+	if len(r.URL.Query()["limit"]) > 0 {
+		limit, _ = strconv.Atoi(r.URL.Query()["limit"][0])
+	}
+
+	if status != "" && status != "open" && status != "closed" {
+		errors.ErrInvalidParam(rw, http.StatusBadRequest, "'status' must be either open or closed")
+		return
+	}
+
+	if limit < 1 {
+		errors.ErrInvalidParam(rw, http.StatusBadRequest, "'limit' cannot be less than 1")
+		return
+	}
+
+	if limit > 100 {
+		errors.ErrOverMaxLimit(rw, http.StatusUnauthorized)
+		return
+	}
+
+	value := map[string]string{"sender": address}
+	if symbol != "" {
+		value["symbol"] = symbol
+	}
+	filter := utils.MsgFilter("dex/NewOrder", value)
+
+	result := &models.ResultAccountOrders{
+		Data: make([]models.AccountOrder, 0),
+	}
+
+	// Status is only known by accelerated node, so new order txs are scanned in batches and
+	// filtered by status until the page is full. Pages end at transaction boundaries
+	cursor := before
+	for scanned := 0; scanned < maxOrderScanTxs; {
+		txs, err := o.db.WithContext(r.Context()).QueryTxsByMsgFilters([]string{filter}, cursor, limit)
+		if err != nil {
+			o.l.Error("failed to query new order txs", "err", err)
+			errors.ErrInternalServer(rw, http.StatusInternalServerError)
+			return
+		}
+
+		if len(txs) <= 0 {
+			cursor = 0
+			break
+		}
+
+		orders, err := o.newOrders(r.Context(), address, txs)
+		if err != nil {
+			o.l.Error("failed to query cancel order txs", "err", err)
+			errors.ErrInternalServer(rw, http.StatusInternalServerError)
+			return
+		}
+
+		for _, tx := range txs {
+			for _, order := range orders[tx.ID] {
+				switch {
+				case status == "open" && !order.IsOpen():
+					continue
+				case status == "closed" && order.IsOpen():
+					continue
+				}
+
+				result.Data = append(result.Data, order)
+			}
+
+			cursor = int(tx.ID)
+			if len(result.Data) >= limit {
+				break
+			}
+		}
+
+		scanned += len(txs)
+
+		if len(result.Data) >= limit {
+			break
+		}
+
+		if len(txs) < limit {
+			cursor = 0
+			break
+		}
+	}
+
+	result.Before = int32(cursor)
+
+	label := addressLabels(r.Context(), o.l, o.db, address)[address]
+	for i := range result.Data {
+		result.Data[i].OwnerLabel = label
+	}
+
+	// Total is only known without a status filter, since it would take requesting every order
+	if status == "" {
+		total, err := o.db.WithContext(r.Context()).CountMsgsByMsgFilter(filter)
+		if err != nil {
+			o.l.Error("failed to count new order msgs", "err", err)
+			errors.ErrInternalServer(rw, http.StatusInternalServerError)
+			return
+		}
+
+		result.Total = &total
+	}
+
+	utils.Respond(rw, result)
+	return
+}
+
+// newOrders parses orders placed by new order txs of an account, keyed by tx id. Cancel txs of the orders
+// are queried by their order ids, and current status of every order is requested concurrently
+func (o *Order) newOrders(ctx context.Context, address string, txs []schema.Transaction) (map[int32][]models.AccountOrder, error) {
+	orders := make(map[int32][]models.AccountOrder)
+	cancelFilters := make([]string, 0)
+
+	for _, tx := range txs {
+		msgs := make([]models.Message, 0)
+		err := json.Unmarshal([]byte(tx.Messages), &msgs)
+		if err != nil {
+			o.l.Error("failed to unmarshal msgs", "err", err)
+			continue
+		}
+
+		for _, msg := range msgs {
+			if msg.Type != "dex/NewOrder" {
+				continue
+			}
+
+			var value models.NewOrderMsgValue
+			err = json.Unmarshal(msg.Value, &value)
+			if err != nil {
+				o.l.Error("failed to unmarshal new order msg", "err", err)
+				continue
+			}
+
+			if value.Sender != address {
+				continue
+			}
+
+			orders[tx.ID] = append(orders[tx.ID], models.AccountOrder{
+				Order: models.Order{
+					OrderID:          value.ID,
+					Symbol:           value.Symbol,
+					Owner:            value.Sender,
+					Price:            fmt.Sprintf("%.8f", float64(value.Price)/1e8),
+					Quantity:         fmt.Sprintf("%.8f", float64(value.Quantity)/1e8),
+					CumulateQuantity: "0.00000000",
+					OrderCreateTime:  tx.Timestamp,
+					Status:           models.OrderStatusUnknown,
+					TimeInForce:      int(value.TimeInForce),
+					Side:             int(value.Side),
+					Type:             int(value.OrderType),
+				},
+				CreateTxHash: tx.TxHash,
+				CreateHeight: tx.Height,
+			})
+
+			cancelFilters = append(cancelFilters, utils.MsgFilter("dex/CancelOrder", map[string]string{"sender": address, "refid": value.ID}))
+		}
+	}
+
+	if len(cancelFilters) <= 0 {
+		return orders, nil
+	}
+
+	cancelOrderTxs, err := o.db.WithContext(ctx).QueryTxsByMsgFilters(cancelFilters, 0, len(cancelFilters))
+	if err != nil {
+		return orders, err
+	}
+
+	// Map canceled order ids to the transactions that canceled them
+	cancels := make(map[string]schema.Transaction)
+	for _, tx := range cancelOrderTxs {
+		msgs := make([]models.Message, 0)
+		err := json.Unmarshal([]byte(tx.Messages), &msgs)
+		if err != nil {
+			o.l.Error("failed to unmarshal msgs", "err", err)
+			continue
+		}
+
+		for _, msg := range msgs {
+			if msg.Type != "dex/CancelOrder" {
+				continue
+			}
+
+			var value models.CancelOrderMsgValue
+			err = json.Unmarshal(msg.Value, &value)
+			if err != nil {
+				o.l.Error("failed to unmarshal cancel order msg", "err", err)
+				continue
+			}
+
+			cancels[value.RefID] = tx
+		}
+	}
+
+	// Request current order status and fills concurrently
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, 10)
+
+	for _, txOrders := range orders {
+		for i := range txOrders {
+			order := &txOrders[i]
+
+			if cancel, ok := cancels[order.OrderID]; ok {
+				order.Status = models.OrderStatusCanceled
+				order.CancelTxHash = cancel.TxHash
+				order.CancelHeight = cancel.Height
+			}
+
+			wg.Add(1)
+			go func(order *models.AccountOrder) {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+
+				o.setOrderStatus(ctx, order)
+			}(order)
+		}
+	}
+
+	wg.Wait()
+
+	return orders, nil
+}
+
+// setOrderStatus sets current order status, cumulative fills and average execution price
+// of an order. Order information parsed from its message is kept when the request fails
+func (o *Order) setOrderStatus(ctx context.Context, order *models.AccountOrder) {
+	current, err := o.client.WithContext(ctx).Order(order.OrderID)
+	if err != nil {
+		o.l.Error("failed to request order information", "order_id", order.OrderID, "err", err)
+		return
+	}
+
+	if current.OrderID == "" {
+		o.l.Debug("order information is not available", "order_id", order.OrderID)
+		return
+	}
+
+	order.Order = current
+
+	cumulateQuantity, _ := strconv.ParseFloat(current.CumulateQuantity, 64)
+	if cumulateQuantity <= 0 {
+		return
+	}
+
+	trades, err := o.client.WithContext(ctx).OrderTrades(order.Owner, order.OrderID, order.Side)
+	if err != nil {
+		o.l.Error("failed to request order trades", "err", err)
+		return
+	}
+
+	var quantity, amount float64
+	for _, trade := range trades {
+		p, _ := strconv.ParseFloat(trade.Price, 64)
+		q, _ := strconv.ParseFloat(trade.Quantity, 64)
+
+		quantity += q
+		amount += p * q
+	}
+
+	if quantity > 0 {
+		order.AvgExecutedPrice = fmt.Sprintf("%.8f", amount/quantity)
+	}
+}
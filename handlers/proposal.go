@@ -0,0 +1,322 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"mintscan/client"
+	"mintscan/db"
+	"mintscan/errors"
+	"mintscan/models"
+	"mintscan/schema"
+	"mintscan/utils"
+
+	"github.com/gorilla/mux"
+
+	"github.com/tendermint/tendermint/libs/log"
+)
+
+// Proposal is a governance proposal handler
+type Proposal struct {
+	l      log.Logger
+	client *client.Client
+	db     *db.Database
+}
+
+// NewProposal creates a new governance proposal handler with the given params
+func NewProposal(l log.Logger, client *client.Client, db *db.Database) *Proposal {
+	return &Proposal{l, client, db}
+}
+
+// GetProposals returns governance proposals on the active chain
+func (p *Proposal) GetProposals(rw http.ResponseWriter, r *http.Request) {
+	proposals, err := p.client.WithContext(r.Context()).Proposals()
+	if err != nil {
+		p.l.Error("failed to request proposals", "err", err)
+		errors.ErrInternalServer(rw, http.StatusInternalServerError)
+		return
+	}
+
+	result := make([]models.ProposalValue, 0)
+	for _, proposal := range proposals {
+		result = append(result, proposal.Value)
+	}
+
+	utils.Respond(rw, result)
+	return
+}
+
+// GetProposal returns a governance proposal with the transaction that submitted it
+// and its tally weighted by validator voting power
+func (p *Proposal) GetProposal(rw http.ResponseWriter, r *http.Request) {
+	proposal, ok := p.proposal(rw, r)
+	if !ok {
+		return
+	}
+
+	result := &models.ResultProposal{
+		ProposalValue: proposal,
+	}
+
+	tx, value, err := p.submitTx(r.Context(), proposal)
+	if err != nil {
+		p.l.Error("failed to query submit proposal txs", "err", err)
+		errors.ErrInternalServer(rw, http.StatusInternalServerError)
+		return
+	}
+
+	if tx != nil {
+		result.Proposer = value.Proposer
+		result.SubmitTxHash = tx.TxHash
+		result.SubmitHeight = tx.Height
+	}
+
+	_, tally, err := p.votes(r.Context(), proposal.ProposalID)
+	if err != nil {
+		p.l.Error("failed to query proposal votes", "err", err)
+		errors.ErrInternalServer(rw, http.StatusInternalServerError)
+		return
+	}
+
+	result.ProposerLabel = addressLabels(r.Context(), p.l, p.db, result.Proposer)[result.Proposer]
+	result.ValidatorTally = tally
+
+	utils.Respond(rw, result)
+	return
+}
+
+// GetProposalVotes returns the latest vote of each voter on a governance proposal
+func (p *Proposal) GetProposalVotes(rw http.ResponseWriter, r *http.Request) {
+	proposal, ok := p.proposal(rw, r)
+	if !ok {
+		return
+	}
+
+	votes, tally, err := p.votes(r.Context(), proposal.ProposalID)
+	if err != nil {
+		p.l.Error("failed to query proposal votes", "err", err)
+		errors.ErrInternalServer(rw, http.StatusInternalServerError)
+		return
+	}
+
+	result := &models.ResultProposalVotes{
+		ProposalID:     proposal.ProposalID,
+		ValidatorTally: tally,
+		Votes:          votes,
+	}
+
+	utils.Respond(rw, result)
+	return
+}
+
+// GetProposalDeposits returns deposits into a governance proposal in chronological order,
+// starting with the initial deposit of the transaction that submitted it
+func (p *Proposal) GetProposalDeposits(rw http.ResponseWriter, r *http.Request) {
+	proposal, ok := p.proposal(rw, r)
+	if !ok {
+		return
+	}
+
+	deposits := make([]models.ProposalDeposit, 0)
+
+	tx, value, err := p.submitTx(r.Context(), proposal)
+	if err != nil {
+		p.l.Error("failed to query submit proposal txs", "err", err)
+		errors.ErrInternalServer(rw, http.StatusInternalServerError)
+		return
+	}
+
+	if tx != nil && len(value.InitialDeposit) > 0 {
+		deposits = append(deposits, models.ProposalDeposit{
+			Depositor: value.Proposer,
+			Amount:    value.InitialDeposit,
+			Initial:   true,
+			Height:    tx.Height,
+			TxHash:    tx.TxHash,
+			Timestamp: tx.Timestamp,
+		})
+	}
+
+	filter := utils.MsgFilter("cosmos-sdk/MsgDeposit", map[string]string{"proposal_id": strconv.FormatInt(proposal.ProposalID, 10)})
+	txs, err := p.db.WithContext(r.Context()).QueryAllTxsByMsgFilters([]string{filter})
+	if err != nil {
+		p.l.Error("failed to query deposit txs", "err", err)
+		errors.ErrInternalServer(rw, http.StatusInternalServerError)
+		return
+	}
+
+	for _, tx := range txs {
+		for _, msg := range unmarshalMsgs(p.l, tx, "cosmos-sdk/MsgDeposit") {
+			var value models.ProposalDepositMsgValue
+			if json.Unmarshal(msg.Value, &value) != nil || value.ProposalID != proposal.ProposalID {
+				continue
+			}
+
+			deposits = append(deposits, models.ProposalDeposit{
+				Depositor: value.Depositer,
+				Amount:    value.Amount,
+				Height:    tx.Height,
+				TxHash:    tx.TxHash,
+				Timestamp: tx.Timestamp,
+			})
+		}
+	}
+
+	result := &models.ResultProposalDeposits{
+		ProposalID: proposal.ProposalID,
+		Deposits:   deposits,
+	}
+
+	utils.Respond(rw, result)
+	return
+}
+
+// proposal parses proposal id in the request path and requests the proposal.
+// It responds an error if the id is invalid or the proposal does not exist
+func (p *Proposal) proposal(rw http.ResponseWriter, r *http.Request) (models.ProposalValue, bool) {
+	vars := mux.Vars(r)
+
+	if vars["id"] == "" {
+		errors.ErrRequiredParam(rw, http.StatusBadRequest, "proposal id is required")
+		return models.ProposalValue{}, false
+	}
+
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil || id <= 0 {
+		errors.ErrInvalidParam(rw, http.StatusBadRequest, "proposal id is invalid")
+		return models.ProposalValue{}, false
+	}
+
+	proposal, err := p.client.WithContext(r.Context()).Proposal(id)
+	if err != nil {
+		p.l.Error("failed to request proposal", "err", err)
+		errors.ErrInternalServer(rw, http.StatusInternalServerError)
+		return models.ProposalValue{}, false
+	}
+
+	if proposal == nil {
+		errors.ErrNotExist(rw, http.StatusNotFound)
+		return models.ProposalValue{}, false
+	}
+
+	return proposal.Value, true
+}
+
+// submitTx returns the transaction that submitted a proposal and its submit message, or nil if it is not indexed.
+// Submit messages don't contain the proposal id assigned by the chain, so the message is matched by title,
+// description and type, and the latest matching transaction at or before submit time of the proposal is taken.
+// This tells apart proposals that reuse the title of an earlier one
+func (p *Proposal) submitTx(ctx context.Context, proposal models.ProposalValue) (*schema.Transaction, models.SubmitProposalMsgValue, error) {
+	var value models.SubmitProposalMsgValue
+
+	filter := utils.MsgFilter("cosmos-sdk/MsgSubmitProposal", map[string]string{
+		"title":         proposal.Title,
+		"description":   proposal.Description,
+		"proposal_type": proposal.ProposalType,
+	})
+
+	txs, err := p.db.WithContext(ctx).QueryTxsByMsgFiltersUntil([]string{filter}, proposal.SubmitTime, 1)
+	if err != nil {
+		return nil, value, err
+	}
+
+	for _, tx := range txs {
+		for _, msg := range unmarshalMsgs(p.l, tx, "cosmos-sdk/MsgSubmitProposal") {
+			if json.Unmarshal(msg.Value, &value) == nil && value.Title == proposal.Title {
+				return &tx, value, nil
+			}
+		}
+	}
+
+	return nil, value, nil
+}
+
+// votes returns the latest vote of each voter on a proposal and the tally of them
+// weighted by voting power of the validators that voters operate
+func (p *Proposal) votes(ctx context.Context, id int64) ([]models.ProposalVote, models.ValidatorTally, error) {
+	var tally models.ValidatorTally
+
+	vals, err := p.db.WithContext(ctx).QueryValidators()
+	if err != nil {
+		return nil, tally, err
+	}
+
+	valsByAccount := make(map[string]*schema.Validator)
+	for _, val := range vals {
+		valsByAccount[val.AccountAddress] = val
+		tally.TotalVotingPower += val.VotingPower
+	}
+
+	filter := utils.MsgFilter("cosmos-sdk/MsgVote", map[string]string{"proposal_id": strconv.FormatInt(id, 10)})
+	txs, err := p.db.WithContext(ctx).QueryAllTxsByMsgFilters([]string{filter})
+	if err != nil {
+		return nil, tally, err
+	}
+
+	// Transactions are queried in ascending order, so they are walked backwards to take the latest vote of each voter
+	votes := make([]models.ProposalVote, 0)
+	voted := make(map[string]bool)
+
+	for j := len(txs) - 1; j >= 0; j-- {
+		tx := txs[j]
+		msgs := unmarshalMsgs(p.l, tx, "cosmos-sdk/MsgVote")
+		for i := len(msgs) - 1; i >= 0; i-- {
+			var value models.VoteMsgValue
+			if json.Unmarshal(msgs[i].Value, &value) != nil || value.ProposalID != id || voted[value.Voter] {
+				continue
+			}
+			voted[value.Voter] = true
+
+			vote := models.ProposalVote{
+				Voter:     value.Voter,
+				Option:    value.Option,
+				Height:    tx.Height,
+				TxHash:    tx.TxHash,
+				Timestamp: tx.Timestamp,
+			}
+
+			if val, ok := valsByAccount[value.Voter]; ok {
+				vote.Moniker = val.Moniker
+				vote.VotingPower = val.VotingPower
+			}
+
+			switch vote.Option {
+			case models.VoteOptionYes:
+				tally.Yes += vote.VotingPower
+			case models.VoteOptionAbstain:
+				tally.Abstain += vote.VotingPower
+			case models.VoteOptionNo:
+				tally.No += vote.VotingPower
+			case models.VoteOptionNoWithVeto:
+				tally.NoWithVeto += vote.VotingPower
+			}
+
+			votes = append(votes, vote)
+		}
+	}
+
+	tally.NotVoted = tally.TotalVotingPower - tally.Yes - tally.Abstain - tally.No - tally.NoWithVeto
+
+	return votes, tally, nil
+}
+
+// unmarshalMsgs returns messages of the given type in a transaction
+func unmarshalMsgs(l log.Logger, tx schema.Transaction, msgType string) []models.Message {
+	msgs := make([]models.Message, 0)
+	err := json.Unmarshal([]byte(tx.Messages), &msgs)
+	if err != nil {
+		l.Error("failed to unmarshal msgs", "err", err)
+		return msgs
+	}
+
+	result := make([]models.Message, 0)
+	for _, msg := range msgs {
+		if msg.Type == msgType {
+			result = append(result, msg)
+		}
+	}
+
+	return result
+}
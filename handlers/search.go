@@ -0,0 +1,300 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"mintscan/client"
+	"mintscan/db"
+	"mintscan/errors"
+	"mintscan/models"
+	"mintscan/utils"
+
+	cmtypes "github.com/binance-chain/go-sdk/common/types"
+
+	"github.com/tendermint/tendermint/libs/log"
+)
+
+var (
+	heightPattern  = regexp.MustCompile(`^[0-9]+$`)
+	hashPattern    = regexp.MustCompile(`^[0-9A-Fa-f]{64}$`)
+	orderIDPattern = regexp.MustCompile(`^[0-9A-Fa-f]{40}-[0-9]+$`)
+	symbolPattern  = regexp.MustCompile(`^[0-9A-Za-z.#-]{1,20}$`)
+)
+
+// Search is a search handler
+type Search struct {
+	l      log.Logger
+	client *client.Client
+	db     *db.Database
+	nt     cmtypes.ChainNetwork
+}
+
+// NewSearch creates a new search handler with the given params
+func NewSearch(l log.Logger, client *client.Client, db *db.Database, network cmtypes.ChainNetwork) *Search {
+	return &Search{l, client, db, network}
+}
+
+// GetSearch classifies the query as block height, tx or block hash, account address, validator operator address,
+// order id or asset symbol, queries the matching sources in parallel and returns ranked results. Validator monikers
+// are matched for every query, since a moniker can look like any other class. Asset symbols and monikers are matched
+// by prefix for autocomplete
+func (s *Search) GetSearch(rw http.ResponseWriter, r *http.Request) {
+	if len(r.URL.Query()["q"]) <= 0 || strings.TrimSpace(r.URL.Query()["q"][0]) == "" {
+		errors.ErrRequiredParam(rw, http.StatusBadRequest, "'q' is not present")
+		return
+	}
+
+	q := strings.TrimSpace(r.URL.Query()["q"][0])
+	limit := 10
+
+	if len(q) > 128 {
+		errors.ErrInvalidParam(rw, http.StatusBadRequest, "'q' cannot be longer than 128 characters")
+		return
+	}
+
+	if len(r.URL.Query()["limit"]) > 0 {
+		limit, _ = strconv.Atoi(r.URL.Query()["limit"][0])
+	}
+
+	if limit < 1 {
+		errors.ErrInvalidParam(rw, http.StatusBadRequest, "'limit' cannot be less than 1")
+		return
+	}
+
+	if limit > 50 {
+		errors.ErrOverMaxLimit(rw, http.StatusUnauthorized)
+		return
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	results := make([]models.SearchResult, 0)
+
+	for _, source := range s.classify(q) {
+		wg.Add(1)
+		go func(source func(context.Context, string) []models.SearchResult) {
+			defer wg.Done()
+
+			found := source(r.Context(), q)
+
+			mu.Lock()
+			results = append(results, found...)
+			mu.Unlock()
+		}(source)
+	}
+
+	wg.Wait()
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Value < results[j].Value
+	})
+
+	if len(results) > limit {
+		results = results[:limit]
+	}
+
+	utils.Respond(rw, &models.ResultSearch{
+		Query:   q,
+		Results: results,
+	})
+	return
+}
+
+// classify returns the sources that can match the query. Monikers are matched for every query
+func (s *Search) classify(q string) []func(context.Context, string) []models.SearchResult {
+	sources := []func(context.Context, string) []models.SearchResult{s.searchMoniker}
+
+	switch {
+	case heightPattern.MatchString(q):
+		sources = append(sources, s.searchHeight)
+	case hashPattern.MatchString(q):
+		sources = append(sources, s.searchTx, s.searchBlockHash)
+	case orderIDPattern.MatchString(q):
+		sources = append(sources, s.searchOrder)
+	case utils.IsAccAddress(s.nt, strings.ToLower(q)):
+		sources = append(sources, s.searchAccount)
+	case utils.IsValAddress(s.nt, strings.ToLower(q)):
+		sources = append(sources, s.searchValidator)
+	case symbolPattern.MatchString(q):
+		sources = append(sources, s.searchAsset)
+	}
+
+	return sources
+}
+
+// searchHeight matches a block height that is not greater than the latest block height
+func (s *Search) searchHeight(ctx context.Context, q string) []models.SearchResult {
+	height, err := strconv.ParseInt(q, 10, 64)
+	if err != nil {
+		return nil
+	}
+
+	latestHeight, err := s.client.WithContext(ctx).LatestBlockHeight()
+	if err != nil {
+		s.l.Error("failed to query latest block height", "err", err)
+		return nil
+	}
+
+	if height < 1 || height > latestHeight {
+		return nil
+	}
+
+	return []models.SearchResult{{Type: models.SearchTypeBlock, Value: q, Score: 100}}
+}
+
+// searchTx matches a transaction hash in database and falls back to the API
+func (s *Search) searchTx(ctx context.Context, q string) []models.SearchResult {
+	hash := strings.ToUpper(q)
+
+	if _, err := s.db.WithContext(ctx).QueryTxByHash(hash); err == nil {
+		return []models.SearchResult{{Type: models.SearchTypeTx, Value: hash, Score: 100}}
+	}
+
+	tx, err := s.client.WithContext(ctx).TxByHash(hash)
+	if err != nil || tx.TxHash == "" {
+		return nil
+	}
+
+	return []models.SearchResult{{Type: models.SearchTypeTx, Value: hash, Score: 100}}
+}
+
+// searchBlockHash matches a block hash in database
+func (s *Search) searchBlockHash(ctx context.Context, q string) []models.SearchResult {
+	block, err := s.db.WithContext(ctx).QueryBlockByHash(strings.ToUpper(q))
+	if err != nil {
+		return nil
+	}
+
+	return []models.SearchResult{{
+		Type:  models.SearchTypeBlock,
+		Value: strconv.FormatInt(block.Height, 10),
+		Name:  block.BlockHash,
+		Score: 90,
+	}}
+}
+
+// searchOrder matches an order id
+func (s *Search) searchOrder(ctx context.Context, q string) []models.SearchResult {
+	order, err := s.client.WithContext(ctx).Order(strings.ToUpper(q))
+	if err != nil || order.OrderID == "" {
+		return nil
+	}
+
+	return []models.SearchResult{{Type: models.SearchTypeOrder, Value: order.OrderID, Name: order.Symbol, Score: 100}}
+}
+
+// searchAccount matches a valid account address. Addresses that are not found on chain rank lower
+func (s *Search) searchAccount(ctx context.Context, q string) []models.SearchResult {
+	address := strings.ToLower(q)
+
+	result := models.SearchResult{
+		Type:  models.SearchTypeAccount,
+		Value: address,
+		Name:  addressLabels(ctx, s.l, s.db, address)[address],
+		Score: 50,
+	}
+
+	account, err := s.client.WithContext(ctx).Account(address)
+	if err == nil && account.Address != "" {
+		result.Score = 100
+	}
+
+	return []models.SearchResult{result}
+}
+
+// searchValidator matches a validator operator address
+func (s *Search) searchValidator(ctx context.Context, q string) []models.SearchResult {
+	val, err := s.client.WithContext(ctx).Validator(strings.ToLower(q))
+	if err != nil || val == nil || val.OperatorAddress == "" {
+		return nil
+	}
+
+	return []models.SearchResult{{
+		Type:  models.SearchTypeValidator,
+		Value: val.OperatorAddress,
+		Name:  val.Description.Moniker,
+		Score: 100,
+	}}
+}
+
+// searchAsset matches asset symbols by exact symbol, original symbol and symbol prefix
+func (s *Search) searchAsset(ctx context.Context, q string) []models.SearchResult {
+	tokens, err := s.client.WithContext(ctx).CachedTokens()
+	if err != nil {
+		s.l.Error("failed to query tokens", "err", err)
+		return nil
+	}
+
+	query := strings.ToUpper(q)
+	results := make([]models.SearchResult, 0)
+
+	for _, token := range tokens {
+		score := 0
+
+		switch {
+		case token.Symbol == query:
+			score = 100
+		case token.OriginalSymbol == query:
+			score = 90
+		case strings.HasPrefix(token.Symbol, query):
+			score = 70
+		default:
+			continue
+		}
+
+		results = append(results, models.SearchResult{
+			Type:  models.SearchTypeAsset,
+			Value: token.Symbol,
+			Name:  token.Name,
+			Score: score,
+		})
+	}
+
+	return results
+}
+
+// searchMoniker matches validator monikers case-insensitively by exact moniker, moniker prefix and substring
+func (s *Search) searchMoniker(ctx context.Context, q string) []models.SearchResult {
+	vals, err := s.client.WithContext(ctx).CachedValidators()
+	if err != nil {
+		s.l.Error("failed to query validators", "err", err)
+		return nil
+	}
+
+	query := strings.ToLower(q)
+	results := make([]models.SearchResult, 0)
+
+	for _, val := range vals {
+		moniker := strings.ToLower(val.Description.Moniker)
+		score := 0
+
+		switch {
+		case moniker == query:
+			score = 95
+		case strings.HasPrefix(moniker, query):
+			score = 60
+		case strings.Contains(moniker, query):
+			score = 40
+		default:
+			continue
+		}
+
+		results = append(results, models.SearchResult{
+			Type:  models.SearchTypeValidator,
+			Value: val.OperatorAddress,
+			Name:  val.Description.Moniker,
+			Score: score,
+		})
+	}
+
+	return results
+}
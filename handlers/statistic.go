@@ -1,70 +1,439 @@
 package handlers
 
 import (
-	"log"
+	"context"
+	"encoding/json"
 	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"mintscan/client"
 	"mintscan/db"
+	"mintscan/errors"
 	"mintscan/models"
+	"mintscan/schema"
 	"mintscan/utils"
+
+	"github.com/tendermint/tendermint/libs/log"
 )
 
 // Statistic is a statistic handler
 type Statistic struct {
-	l      *log.Logger
-	client *client.Client
-	db     *db.Database
+	l        log.Logger
+	client   *client.Client
+	db       *db.Database
+	featured []string
 }
 
+// maxChartPoints bounds the number of prices of each asset in a chart history
+const maxChartPoints = 1000
+
 // NewStatistic creates a new statistic handler with the given params
-func NewStatistic(l *log.Logger, client *client.Client, db *db.Database) *Statistic {
-	return &Statistic{l, client, db}
+func NewStatistic(l log.Logger, client *client.Client, db *db.Database) *Statistic {
+	return &Statistic{l: l, client: client, db: db}
 }
 
-// GetAssetsChartHistory returns
+// WithFeaturedAssets returns a copy of the handler that falls back to the given featured assets
+// when no featured asset is saved in database
+func (s *Statistic) WithFeaturedAssets(featured []string) *Statistic {
+	c := *s
+	c.featured = featured
+	return &c
+}
+
+// GetAssetsChartHistory returns price history of featured assets or the requested assets
+// in a time range. Hourly statistics are used for 24h and 7d ranges and daily statistics otherwise
 func (s *Statistic) GetAssetsChartHistory(rw http.ResponseWriter, r *http.Request) {
-	rw.Header().Set("Access-Control-Allow-Origin", "*")
-	result := make([]models.AssetChartHistory, 0)
+	chartRange := "24h"
+	interval := ""
 
-	limit := int(24)
+	if len(r.URL.Query()["range"]) > 0 {
+		chartRange = r.URL.Query()["range"][0]
+	}
 
-	for _, assetName := range models.ChosenAssetNames {
-		asset, err := s.client.Asset(assetName)
-		if err != nil {
-			s.l.Printf("failed to get asset detail information: %s\n", err)
+	if len(r.URL.Query()["interval"]) > 0 {
+		interval = r.URL.Query()["interval"][0]
+	}
+
+	duration, ok := models.ChartRanges[chartRange]
+	if !ok {
+		errors.ErrInvalidParam(rw, http.StatusBadRequest, "'range' must be one of 24h, 7d, 30d and 1y")
+		return
+	}
+
+	if interval == "" {
+		interval = "1h"
+		if duration > 7*24*time.Hour {
+			interval = "1d"
 		}
+	}
+
+	if interval != "1h" && interval != "1d" {
+		errors.ErrInvalidParam(rw, http.StatusBadRequest, "'interval' must be either 1h or 1d")
+		return
+	}
+
+	step := time.Hour
+	if interval == "1d" {
+		step = 24 * time.Hour
+	}
+
+	if duration/step > maxChartPoints {
+		errors.ErrInvalidParam(rw, http.StatusBadRequest, "'interval' is too short for 'range'")
+		return
+	}
+
+	var assetNames []string
+	if len(r.URL.Query()["assets"]) > 0 && r.URL.Query()["assets"][0] != "" {
+		assetNames = strings.Split(r.URL.Query()["assets"][0], ",")
+	} else {
+		assetNames = s.featuredAssets(r.Context())
+	}
+
+	if len(assetNames) > 20 {
+		errors.ErrOverMaxLimit(rw, http.StatusBadRequest)
+		return
+	}
+
+	since := time.Now().UTC().Add(-duration)
+	result := make([]models.AssetChartHistory, len(assetNames))
+
+	var wg sync.WaitGroup
+	for i, assetName := range assetNames {
+		wg.Add(1)
+		go func(i int, assetName string) {
+			defer wg.Done()
+			result[i] = s.assetChartHistory(r.Context(), assetName, interval, since)
+		}(i, strings.TrimSpace(assetName))
+	}
+
+	wg.Wait()
+
+	utils.Respond(rw, result)
+	return
+}
+
+// featuredAssets returns featured asset names saved in database, configured ones
+// or the default ones in that priority
+func (s *Statistic) featuredAssets(ctx context.Context) []string {
+	names, err := s.db.WithContext(ctx).QueryFeaturedAssets()
+	if err != nil {
+		s.l.Error("failed to query featured assets", "err", err)
+	}
+
+	if len(names) > 0 {
+		return names
+	}
 
-		charts, err := s.db.QueryAssetChartHistory(assetName, limit)
+	if len(s.featured) > 0 {
+		return s.featured
+	}
+
+	return models.ChosenAssetNames
+}
+
+// assetChartHistory returns asset detail information with its price history since the given time
+func (s *Statistic) assetChartHistory(ctx context.Context, assetName string, interval string, since time.Time) models.AssetChartHistory {
+	asset, err := s.client.WithContext(ctx).Asset(assetName)
+	if err != nil {
+		s.l.Error("failed to get asset detail information", "err", err)
+	}
+
+	prices := make([]models.Prices, 0)
+
+	switch interval {
+	case "1d":
+		charts, err := s.db.WithContext(ctx).QueryAssetChartHistory24H(assetName, since)
 		if err != nil {
-			s.l.Printf("failed to query asset chart history: %s", err)
+			s.l.Error("failed to query asset chart history", "err", err)
 		}
 
-		prices := make([]models.Prices, 0)
+		for _, chart := range charts {
+			prices = append(prices, models.Prices{
+				Price:     chart.Price,
+				Timestamp: chart.Timestamp,
+			})
+		}
+	default:
+		charts, err := s.db.WithContext(ctx).QueryAssetChartHistory(assetName, since)
+		if err != nil {
+			s.l.Error("failed to query asset chart history", "err", err)
+		}
 
 		for _, chart := range charts {
-			tempPrices := &models.Prices{
+			prices = append(prices, models.Prices{
 				Price:     chart.Price,
 				Timestamp: chart.Timestamp,
-			}
+			})
+		}
+	}
 
-			prices = append(prices, *tempPrices)
+	return models.AssetChartHistory{
+		Name:         asset.Name,
+		Asset:        asset.Asset,
+		MappedAsset:  asset.MappedAsset,
+		CurrentPrice: asset.Price,
+		QuoteUnit:    asset.QuoteUnit,
+		ChangeRange:  asset.ChangeRange,
+		Supply:       asset.Supply,
+		Marketcap:    asset.Price * asset.Supply,
+		AssetImage:   asset.AssetImg,
+		Prices:       prices,
+	}
+}
+
+// GetCandles returns OHLCV candles of a symbol in an interval between from and to.
+// Intervals without any trade are filled with the close price of the previous candle
+func (s *Statistic) GetCandles(rw http.ResponseWriter, r *http.Request) {
+	if len(r.URL.Query()["symbol"]) <= 0 {
+		errors.ErrRequiredParam(rw, http.StatusBadRequest, "'symbol' is not present")
+		return
+	}
+
+	symbol := r.URL.Query()["symbol"][0]
+	interval := "1h"
+
+	if len(r.URL.Query()["interval"]) > 0 {
+		interval = r.URL.Query()["interval"][0]
+	}
+
+	duration, ok := models.CandleIntervals[interval]
+	if !ok {
+		errors.ErrInvalidParam(rw, http.StatusBadRequest, "'interval' must be one of 1m, 5m, 1h and 1d")
+		return
+	}
+
+	limit := int64(1000) // max number of candles in a response
+
+	to := time.Now().UTC()
+	if len(r.URL.Query()["to"]) > 0 {
+		t, err := strconv.ParseInt(r.URL.Query()["to"][0], 10, 64)
+		if err != nil {
+			errors.ErrInvalidParam(rw, http.StatusBadRequest, "'to' must be unix time")
+			return
 		}
+		to = time.Unix(t, 0).UTC()
+	}
 
-		tempResult := &models.AssetChartHistory{
-			Name:         asset.Name,
-			Asset:        asset.Asset,
-			MappedAsset:  asset.MappedAsset,
-			CurrentPrice: asset.Price,
-			QuoteUnit:    asset.QuoteUnit,
-			ChangeRange:  asset.ChangeRange,
-			Supply:       asset.Supply,
-			Marketcap:    asset.Price * asset.Supply,
-			AssetImage:   asset.AssetImg,
-			Prices:       prices,
+	from := to.Add(-100 * duration)
+	if len(r.URL.Query()["from"]) > 0 {
+		f, err := strconv.ParseInt(r.URL.Query()["from"][0], 10, 64)
+		if err != nil {
+			errors.ErrInvalidParam(rw, http.StatusBadRequest, "'from' must be unix time")
+			return
 		}
+		from = time.Unix(f, 0).UTC()
+	}
+
+	from = from.Truncate(duration)
+	to = to.Truncate(duration)
+
+	if from.After(to) {
+		errors.ErrInvalidParam(rw, http.StatusBadRequest, "'from' cannot be later than 'to'")
+		return
+	}
+
+	if int64(to.Sub(from)/duration) >= limit {
+		errors.ErrOverMaxLimit(rw, http.StatusBadRequest)
+		return
+	}
+
+	candles, err := s.db.WithContext(r.Context()).QueryCandles(symbol, interval, from, to)
+	if err != nil {
+		s.l.Error("failed to query candles", "err", err)
+		errors.ErrInternalServer(rw, http.StatusInternalServerError)
+		return
+	}
+
+	prev, err := s.db.WithContext(r.Context()).QueryCandleBefore(symbol, interval, from)
+	if err != nil {
+		s.l.Error("failed to query previous candle", "err", err)
+		errors.ErrInternalServer(rw, http.StatusInternalServerError)
+		return
+	}
+
+	var prevClose string
+	if prev != nil {
+		prevClose = prev.Close
+	}
+
+	data := fillCandles(candles, prevClose, from, to, duration)
+
+	result := &models.ResultCandles{
+		Symbol:   symbol,
+		Interval: interval,
+		Data:     data,
+	}
+
+	utils.Respond(rw, result)
+	return
+}
+
+// fillCandles returns candles opened every duration from from to to, filling intervals without any trade
+// with the close price of the previous candle, which is prevClose for leading intervals.
+// Intervals before the first trade are left out
+func fillCandles(candles []schema.Candle, prevClose string, from time.Time, to time.Time, duration time.Duration) []models.Candle {
+	data := make([]models.Candle, 0)
+	i := 0
+
+	for openTime := from; !openTime.After(to); openTime = openTime.Add(duration) {
+		if i < len(candles) && candles[i].OpenTime.Equal(openTime) {
+			c := candles[i]
+			data = append(data, models.Candle{
+				OpenTime:    c.OpenTime,
+				Open:        c.Open,
+				High:        c.High,
+				Low:         c.Low,
+				Close:       c.Close,
+				Volume:      c.Volume,
+				QuoteVolume: c.QuoteVolume,
+				NumTrades:   c.NumTrades,
+			})
+			prevClose = c.Close
+			i++
+			continue
+		}
+
+		// No trade is executed before this interval
+		if prevClose == "" {
+			continue
+		}
+
+		data = append(data, models.Candle{
+			OpenTime:    openTime,
+			Open:        prevClose,
+			High:        prevClose,
+			Low:         prevClose,
+			Close:       prevClose,
+			Volume:      "0",
+			QuoteVolume: "0",
+		})
+	}
+
+	return data
+}
+
+// GetNetworkStats returns a network metric aggregated in an interval between from and to.
+// Intervals without any block or transaction are returned as zero
+func (s *Statistic) GetNetworkStats(rw http.ResponseWriter, r *http.Request) {
+	if len(r.URL.Query()["metric"]) <= 0 {
+		errors.ErrRequiredParam(rw, http.StatusBadRequest, "'metric' is not present")
+		return
+	}
+
+	metric := r.URL.Query()["metric"][0]
+	interval := "1d"
+
+	valid := false
+	for _, m := range models.NetworkMetrics {
+		if m == metric {
+			valid = true
+		}
+	}
+
+	if !valid {
+		errors.ErrInvalidParam(rw, http.StatusBadRequest, "'metric' must be one of "+strings.Join(models.NetworkMetrics, ", "))
+		return
+	}
+
+	if len(r.URL.Query()["interval"]) > 0 {
+		interval = r.URL.Query()["interval"][0]
+	}
+
+	duration, ok := models.NetworkStatIntervals[interval]
+	if !ok {
+		errors.ErrInvalidParam(rw, http.StatusBadRequest, "'interval' must be one of 1h and 1d")
+		return
+	}
+
+	limit := int64(1000) // max number of intervals in a response
+
+	to := time.Now().UTC()
+	if len(r.URL.Query()["to"]) > 0 {
+		t, err := strconv.ParseInt(r.URL.Query()["to"][0], 10, 64)
+		if err != nil {
+			errors.ErrInvalidParam(rw, http.StatusBadRequest, "'to' must be unix time")
+			return
+		}
+		to = time.Unix(t, 0).UTC()
+	}
+
+	from := to.Add(-30 * duration)
+	if len(r.URL.Query()["from"]) > 0 {
+		f, err := strconv.ParseInt(r.URL.Query()["from"][0], 10, 64)
+		if err != nil {
+			errors.ErrInvalidParam(rw, http.StatusBadRequest, "'from' must be unix time")
+			return
+		}
+		from = time.Unix(f, 0).UTC()
+	}
+
+	from = from.Truncate(duration)
+	to = to.Truncate(duration)
+
+	if from.After(to) {
+		errors.ErrInvalidParam(rw, http.StatusBadRequest, "'from' cannot be later than 'to'")
+		return
+	}
+
+	if int64(to.Sub(from)/duration) >= limit {
+		errors.ErrOverMaxLimit(rw, http.StatusBadRequest)
+		return
+	}
+
+	stats, err := s.db.WithContext(r.Context()).QueryNetworkStats(interval, from, to)
+	if err != nil {
+		s.l.Error("failed to query network stats", "err", err)
+		errors.ErrInternalServer(rw, http.StatusInternalServerError)
+		return
+	}
+
+	data := make([]models.NetworkStatPoint, 0)
+	i := 0
+
+	for t := from; !t.After(to); t = t.Add(duration) {
+		point := models.NetworkStatPoint{Time: t}
+
+		if i < len(stats) && stats[i].Time.Equal(t) {
+			stat := stats[i]
+
+			switch metric {
+			case "txs":
+				point.Value = float64(stat.NumTxs)
+			case "active_addresses":
+				point.Value = float64(stat.ActiveAddresses)
+			case "new_addresses":
+				point.Value = float64(stat.NewAddresses)
+			case "msg_types":
+				point.Values = make(map[string]int64)
+				err := json.Unmarshal([]byte(stat.MsgTypes), &point.Values)
+				if err != nil {
+					s.l.Error("failed to unmarshal msg types", "err", err)
+				}
+
+				for _, num := range point.Values {
+					point.Value += float64(num)
+				}
+			case "est_avg_fee":
+				point.Value = stat.EstAvgFee
+			case "avg_block_time":
+				point.Value = stat.AvgBlockTime
+			case "total_gas":
+				point.Value = float64(stat.TotalGas)
+			}
+
+			i++
+		}
+
+		data = append(data, point)
+	}
 
-		result = append(result, *tempResult)
+	result := &models.ResultNetworkStats{
+		Metric:   metric,
+		Interval: interval,
+		Data:     data,
 	}
 
 	utils.Respond(rw, result)
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"mintscan/models"
+	"mintscan/schema"
+)
+
+func TestFillCandles(t *testing.T) {
+	from := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	hour := time.Hour
+
+	candle := func(h int, close string) schema.Candle {
+		return schema.Candle{
+			OpenTime:    from.Add(time.Duration(h) * hour),
+			Open:        "1",
+			High:        "2",
+			Low:         "0.5",
+			Close:       close,
+			Volume:      "10",
+			QuoteVolume: "15",
+			NumTrades:   3,
+		}
+	}
+
+	traded := func(h int, close string) models.Candle {
+		return models.Candle{
+			OpenTime:    from.Add(time.Duration(h) * hour),
+			Open:        "1",
+			High:        "2",
+			Low:         "0.5",
+			Close:       close,
+			Volume:      "10",
+			QuoteVolume: "15",
+			NumTrades:   3,
+		}
+	}
+
+	filled := func(h int, price string) models.Candle {
+		return models.Candle{
+			OpenTime:    from.Add(time.Duration(h) * hour),
+			Open:        price,
+			High:        price,
+			Low:         price,
+			Close:       price,
+			Volume:      "0",
+			QuoteVolume: "0",
+		}
+	}
+
+	tests := []struct {
+		name      string
+		candles   []schema.Candle
+		prevClose string
+		to        time.Time
+		want      []models.Candle
+	}{
+		{
+			name: "no trades",
+			to:   from.Add(2 * hour),
+			want: []models.Candle{},
+		},
+		{
+			name:      "no trades in range after previous candle",
+			prevClose: "1.5",
+			to:        from.Add(1 * hour),
+			want:      []models.Candle{filled(0, "1.5"), filled(1, "1.5")},
+		},
+		{
+			name:    "intervals before the first trade are left out",
+			candles: []schema.Candle{candle(1, "1.2")},
+			to:      from.Add(2 * hour),
+			want:    []models.Candle{traded(1, "1.2"), filled(2, "1.2")},
+		},
+		{
+			name:      "gaps are filled with the previous close",
+			candles:   []schema.Candle{candle(0, "1.2"), candle(3, "1.8")},
+			prevClose: "1.1",
+			to:        from.Add(4 * hour),
+			want:      []models.Candle{traded(0, "1.2"), filled(1, "1.2"), filled(2, "1.2"), traded(3, "1.8"), filled(4, "1.8")},
+		},
+		{
+			name:    "every interval traded",
+			candles: []schema.Candle{candle(0, "1.2"), candle(1, "1.3")},
+			to:      from.Add(1 * hour),
+			want:    []models.Candle{traded(0, "1.2"), traded(1, "1.3")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fillCandles(tt.candles, tt.prevClose, from, tt.to, hour)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("fillCandles() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
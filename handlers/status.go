@@ -1,62 +1,175 @@
 package handlers
 
 import (
-	"log"
+	"context"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"mintscan/client"
 	"mintscan/db"
 	"mintscan/models"
 	"mintscan/utils"
+
+	"github.com/tendermint/tendermint/libs/log"
 )
 
+// defaultBlockTimeWindow is the number of blocks to average block time over when it is not configured
+const defaultBlockTimeWindow = 100
+
 // Status is a status handler
 type Status struct {
-	l      *log.Logger
+	l      log.Logger
 	client *client.Client
 	db     *db.Database
+	window int64
 }
 
 // NewStatus creates a new Status handler with the given params
-func NewStatus(l *log.Logger, client *client.Client, db *db.Database) *Status {
-	return &Status{l, client, db}
+func NewStatus(l log.Logger, client *client.Client, db *db.Database, window int64) *Status {
+	if window <= 0 {
+		window = defaultBlockTimeWindow
+	}
+
+	return &Status{l, client, db, window}
 }
 
-// GetStatus returns current status on the active chain
+// GetStatus returns current status on the active chain. When upstream sources fail,
+// the data that could be collected is returned and the failed sources are reported as degraded
 func (s *Status) GetStatus(rw http.ResponseWriter, r *http.Request) {
-	rw.Header().Set("Access-Control-Allow-Origin", "*")
+	result := &models.Status{
+		BlockTimeWindow: s.window,
+	}
 
-	status, err := s.client.Status()
+	status, err := s.client.WithContext(r.Context()).Status()
 	if err != nil {
-		s.l.Printf("failed to query status: %s\n", err)
+		s.l.Error("failed to query status", "err", err)
+		s.degrade(result, "node")
+	} else {
+		result.ChainID = status.NodeInfo.Network
+		result.LatestBlockHeight = status.SyncInfo.LatestBlockHeight
+		result.Timestamp = status.SyncInfo.LatestBlockTime
 	}
 
-	validatorSet, err := s.client.ValidatorSet(status.SyncInfo.LatestBlockHeight)
+	indexedHeight, err := s.db.WithContext(r.Context()).QueryLatestBlockHeight()
 	if err != nil {
-		s.l.Printf("failed to query validators et: %s\n", err)
+		s.l.Error("failed to query latest indexed block height", "err", err)
+		s.degrade(result, "database")
+	} else {
+		result.IndexedBlockHeight = indexedHeight
 	}
 
-	block, err := s.client.Block(status.SyncInfo.LatestBlockHeight)
-	if err != nil {
-		s.l.Printf("failed to query block: %s\n", err)
+	if result.LatestBlockHeight > 0 && result.IndexedBlockHeight > 0 {
+		result.IndexerLag = result.LatestBlockHeight - result.IndexedBlockHeight
 	}
 
-	prevBlock, err := s.client.Block(status.SyncInfo.LatestBlockHeight - 1)
-	if err != nil {
-		s.l.Printf("failed to query previous block: %s\n", err)
+	// Block statistics are still available from indexed blocks when the node is unreachable
+	height := result.LatestBlockHeight
+	if height <= 0 {
+		height = result.IndexedBlockHeight
 	}
 
-	blockTime := block.Block.Time.UTC().
-		Sub(prevBlock.Block.Time.UTC()).Seconds()
+	if height > 0 {
+		validatorSet, err := s.client.WithContext(r.Context()).ValidatorSet(height)
+		if err != nil {
+			s.l.Error("failed to query validator set", "err", err)
+			s.degrade(result, "validator set")
+		} else {
+			result.TotalValidatorNum = len(validatorSet.Validators)
+		}
 
-	result := &models.Status{
-		ChainID:           status.NodeInfo.Network,
-		BlockTime:         blockTime,
-		LatestBlockHeight: status.SyncInfo.LatestBlockHeight,
-		TotalValidatorNum: len(validatorSet.Validators),
-		Timestamp:         status.SyncInfo.LatestBlockTime,
+		err = s.setBlockStats(r.Context(), result, height)
+		if err != nil {
+			s.l.Error("failed to calculate block time", "err", err)
+			s.degrade(result, "blocks")
+		}
+	}
+
+	vals, err := s.client.WithContext(r.Context()).Validators()
+	if err != nil {
+		s.l.Error("failed to query validators", "err", err)
+		s.degrade(result, "validators")
+	} else {
+		for _, val := range vals {
+			if val.Jailed || !(strings.EqualFold(val.Status, "Bonded") || val.Status == "2") {
+				continue
+			}
+
+			// Tokens are either decimal amount or integer amount in 1e8 units
+			tokens, err := strconv.ParseFloat(val.Tokens, 64)
+			if err != nil {
+				continue
+			}
+
+			if !strings.Contains(val.Tokens, ".") {
+				tokens = tokens / 1e8
+			}
+
+			result.BondedTokens += tokens
+		}
 	}
 
 	utils.Respond(rw, result)
 	return
 }
+
+// setBlockStats sets block time averaged over the block time window ending at the given height
+// and transactions per second over the same window
+func (s *Status) setBlockStats(ctx context.Context, result *models.Status, height int64) error {
+	from := height - s.window
+	if from < 1 {
+		from = 1
+	}
+
+	if from >= height {
+		return nil
+	}
+
+	latestTime, latestTotalTxs, err := s.blockHeader(ctx, height)
+	if err != nil {
+		return err
+	}
+
+	fromTime, fromTotalTxs, err := s.blockHeader(ctx, from)
+	if err != nil {
+		return err
+	}
+
+	seconds := latestTime.Sub(fromTime).Seconds()
+	if seconds <= 0 {
+		return fmt.Errorf("invalid block times between height %d and %d", from, height)
+	}
+
+	result.BlockTime = seconds / float64(height-from)
+	result.TxsPerSecond = float64(latestTotalTxs-fromTotalTxs) / seconds
+
+	if result.Timestamp.IsZero() {
+		result.Timestamp = latestTime
+	}
+
+	return nil
+}
+
+// blockHeader returns time and total number of transactions of a block given its height,
+// which are requested from the node and fall back to indexed blocks
+func (s *Status) blockHeader(ctx context.Context, height int64) (time.Time, int64, error) {
+	block, err := s.client.WithContext(ctx).Block(height)
+	if err == nil {
+		return block.Block.Time.UTC(), block.Block.TotalTxs, nil
+	}
+
+	indexed, dbErr := s.db.WithContext(ctx).QueryBlockByHeight(height)
+	if dbErr != nil {
+		return time.Time{}, 0, fmt.Errorf("failed to query block %d: %s", height, err)
+	}
+
+	return indexed.Timestamp.UTC(), indexed.TotalTxs, nil
+}
+
+// degrade marks the given source as failed
+func (s *Status) degrade(result *models.Status, source string) {
+	result.Degraded = true
+	result.DegradedSources = append(result.DegradedSources, source)
+}
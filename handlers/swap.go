@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"mintscan/client"
+	"mintscan/db"
+	"mintscan/errors"
+	"mintscan/models"
+	"mintscan/schema"
+	"mintscan/utils"
+
+	"github.com/gorilla/mux"
+
+	cmtypes "github.com/binance-chain/go-sdk/common/types"
+
+	"github.com/tendermint/tendermint/libs/log"
+)
+
+// Swap is an atomic swap handler
+type Swap struct {
+	l      log.Logger
+	client *client.Client
+	db     *db.Database
+	nt     cmtypes.ChainNetwork
+}
+
+// NewSwap creates a new atomic swap handler with the given params
+func NewSwap(l log.Logger, client *client.Client, db *db.Database, network cmtypes.ChainNetwork) *Swap {
+	return &Swap{l, client, db, network}
+}
+
+// GetSwaps returns atomic swaps based upon the request params
+func (s *Swap) GetSwaps(rw http.ResponseWriter, r *http.Request) {
+	s.respondSwaps(rw, r, "")
+	return
+}
+
+// GetAccountSwaps returns atomic swaps sent or received by an account
+func (s *Swap) GetAccountSwaps(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	address := vars["address"]
+
+	if address == "" {
+		errors.ErrRequiredParam(rw, http.StatusBadRequest, "address is required")
+		return
+	}
+
+	if !utils.IsAccAddress(s.nt, address) {
+		errors.ErrInvalidParam(rw, http.StatusBadRequest, "address is invalid")
+		return
+	}
+
+	s.respondSwaps(rw, r, address)
+	return
+}
+
+// GetSwap returns an atomic swap by its swap id
+func (s *Swap) GetSwap(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := strings.ToLower(vars["id"])
+
+	if id == "" {
+		errors.ErrRequiredParam(rw, http.StatusBadRequest, "swap id is required")
+		return
+	}
+
+	swap, err := s.db.WithContext(r.Context()).QueryAtomicSwap(id)
+	if err != nil {
+		s.l.Error("failed to query atomic swap", "err", err)
+		errors.ErrInternalServer(rw, http.StatusInternalServerError)
+		return
+	}
+
+	if swap == nil {
+		errors.ErrNotExist(rw, http.StatusNotFound)
+		return
+	}
+
+	swaps := s.setSwaps(r.Context(), []schema.AtomicSwap{*swap})
+
+	utils.Respond(rw, swaps[0])
+	return
+}
+
+// respondSwaps responds atomic swaps with pagination params
+func (s *Swap) respondSwaps(rw http.ResponseWriter, r *http.Request, address string) {
+	before := int(0)
+	limit := int(50)
+
+	if len(r.URL.Query()["before"]) > 0 {
+		before, _ = strconv.Atoi(r.URL.Query()["before"][0])
+	}
+
+	if len(r.URL.Query()["limit"]) > 0 {
+		limit, _ = strconv.Atoi(r.URL.Query()["limit"][0])
+	}
+
+	if limit < 1 {
+		errors.ErrInvalidParam(rw, http.StatusBadRequest, "'limit' cannot be less than 1")
+		return
+	}
+
+	if limit > 100 {
+		errors.ErrOverMaxLimit(rw, http.StatusUnauthorized)
+		return
+	}
+
+	swaps, err := s.db.WithContext(r.Context()).QueryAtomicSwaps(address, before, limit)
+	if err != nil {
+		s.l.Error("failed to query atomic swaps", "err", err)
+		errors.ErrInternalServer(rw, http.StatusInternalServerError)
+		return
+	}
+
+	result := &models.ResultSwaps{
+		Data: s.setSwaps(r.Context(), swaps),
+	}
+
+	if len(result.Data) > 0 {
+		result.Paging.Total = int32(len(result.Data))
+		result.Paging.Before = result.Data[len(result.Data)-1].ID
+		result.Paging.After = result.Data[0].ID
+	}
+
+	utils.Respond(rw, result)
+}
+
+// setSwaps links atomic swaps with their deposits and computes their current status
+// based upon the latest indexed block height
+func (s *Swap) setSwaps(ctx context.Context, swaps []schema.AtomicSwap) []models.Swap {
+	latestHeight, err := s.db.WithContext(ctx).QueryLatestBlockHeight()
+	if err != nil {
+		s.l.Error("failed to query latest block height", "err", err)
+	}
+
+	swapIDs := make([]string, 0)
+	for _, swap := range swaps {
+		swapIDs = append(swapIDs, swap.SwapID)
+	}
+
+	deposits, err := s.db.WithContext(ctx).QueryAtomicSwapDeposits(swapIDs)
+	if err != nil {
+		s.l.Error("failed to query atomic swap deposits", "err", err)
+	}
+
+	addresses := make([]string, 0)
+	for _, swap := range swaps {
+		addresses = append(addresses, swap.From, swap.To)
+	}
+	for _, deposit := range deposits {
+		addresses = append(addresses, deposit.From)
+	}
+
+	labels := addressLabels(ctx, s.l, s.db, addresses...)
+
+	depositsBySwap := make(map[string][]models.SwapDeposit)
+	for _, deposit := range deposits {
+		amount := make([]models.Coin, 0)
+		json.Unmarshal([]byte(deposit.Amount), &amount)
+
+		depositsBySwap[deposit.SwapID] = append(depositsBySwap[deposit.SwapID], models.SwapDeposit{
+			From:      deposit.From,
+			FromLabel: labels[deposit.From],
+			Amount:    amount,
+			Height:    deposit.Height,
+			TxHash:    deposit.TxHash,
+			Timestamp: deposit.Timestamp,
+		})
+	}
+
+	data := make([]models.Swap, 0)
+	for _, swap := range swaps {
+		amount := make([]models.Coin, 0)
+		json.Unmarshal([]byte(swap.Amount), &amount)
+
+		status := swap.Status
+		expireHeight := swap.Height + swap.HeightSpan
+		if status == models.SwapStatusOpen && latestHeight >= expireHeight {
+			status = models.SwapStatusExpired
+		}
+
+		swapDeposits := depositsBySwap[swap.SwapID]
+		if swapDeposits == nil {
+			swapDeposits = make([]models.SwapDeposit, 0)
+		}
+
+		data = append(data, models.Swap{
+			ID:                  swap.ID,
+			SwapID:              swap.SwapID,
+			From:                swap.From,
+			FromLabel:           labels[swap.From],
+			To:                  swap.To,
+			ToLabel:             labels[swap.To],
+			RecipientOtherChain: swap.RecipientOtherChain,
+			SenderOtherChain:    swap.SenderOtherChain,
+			RandomNumberHash:    swap.RandomNumberHash,
+			RandomNumber:        swap.RandomNumber,
+			SwapTimestamp:       swap.SwapTimestamp,
+			Amount:              amount,
+			ExpectedIncome:      swap.ExpectedIncome,
+			HeightSpan:          swap.HeightSpan,
+			ExpireHeight:        expireHeight,
+			CrossChain:          swap.CrossChain,
+			Status:              status,
+			Height:              swap.Height,
+			TxHash:              swap.TxHash,
+			ClaimTxHash:         swap.ClaimTxHash,
+			ClaimHeight:         swap.ClaimHeight,
+			RefundTxHash:        swap.RefundTxHash,
+			RefundHeight:        swap.RefundHeight,
+			Deposits:            swapDeposits,
+			Timestamp:           swap.Timestamp,
+		})
+	}
+
+	return data
+}
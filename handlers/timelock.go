@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"mintscan/db"
+	"mintscan/models"
+	"mintscan/schema"
+	"mintscan/utils"
+)
+
+// queryTimeLocks queries successful time lock transactions sent by an account in chronological order
+// and reconstructs its time locks from them
+func queryTimeLocks(db *db.Database, address string) ([]*models.TimeLock, error) {
+	value := map[string]string{"from": address}
+	filters := []string{
+		utils.MsgFilter("tokens/TimeLockMsg", value),
+		utils.MsgFilter("tokens/TimeRelockMsg", value),
+		utils.MsgFilter("tokens/TimeUnlockMsg", value),
+	}
+
+	txs, err := db.QueryAllTxsByMsgFilters(filters)
+	if err != nil {
+		return nil, err
+	}
+
+	return reconstructTimeLocks(address, txs)
+}
+
+// reconstructTimeLocks reconstructs time locks of an account from its successful time lock transactions
+// in chronological order. Lock ids are assigned the same way as the chain does, which is the largest id
+// among active locks plus one
+func reconstructTimeLocks(address string, txs []schema.Transaction) ([]*models.TimeLock, error) {
+	locks := make([]*models.TimeLock, 0)
+	active := make(map[int64]*models.TimeLock)
+
+	for _, tx := range txs {
+		msgs := make([]models.Message, 0)
+		err := json.Unmarshal([]byte(tx.Messages), &msgs)
+		if err != nil {
+			return locks, fmt.Errorf("failed to unmarshal msgs: %s", err)
+		}
+
+		for _, msg := range msgs {
+			var value models.TimeLockMsgValue
+
+			switch msg.Type {
+			case "tokens/TimeLockMsg", "tokens/TimeRelockMsg", "tokens/TimeUnlockMsg":
+				err = json.Unmarshal(msg.Value, &value)
+				if err != nil {
+					return locks, fmt.Errorf("failed to unmarshal time lock msg: %s", err)
+				}
+			default:
+				continue
+			}
+
+			if value.From != address {
+				continue
+			}
+
+			switch msg.Type {
+			case "tokens/TimeLockMsg":
+				id := int64(0)
+				for activeID := range active {
+					if activeID > id {
+						id = activeID
+					}
+				}
+
+				lock := &models.TimeLock{
+					ID:           id + 1,
+					From:         value.From,
+					Description:  value.Description,
+					Amount:       value.Amount,
+					LockTime:     time.Unix(value.LockTime, 0).UTC(),
+					Status:       models.TimeLockStatusLocked,
+					CreateTxHash: tx.TxHash,
+					CreateHeight: tx.Height,
+					CreateTime:   tx.Timestamp,
+				}
+
+				active[lock.ID] = lock
+				locks = append(locks, lock)
+
+			case "tokens/TimeRelockMsg":
+				lock, ok := active[value.ID]
+				if !ok {
+					continue
+				}
+
+				if value.Description != "" {
+					lock.Description = value.Description
+				}
+
+				if value.LockTime > 0 {
+					lock.LockTime = time.Unix(value.LockTime, 0).UTC()
+				}
+
+				if len(value.Amount) > 0 {
+					lock.Amount = value.Amount
+				}
+
+			case "tokens/TimeUnlockMsg":
+				lock, ok := active[value.ID]
+				if !ok {
+					continue
+				}
+
+				lock.Status = models.TimeLockStatusUnlocked
+				lock.UnlockTxHash = tx.TxHash
+				lock.UnlockHeight = tx.Height
+				delete(active, value.ID)
+			}
+		}
+	}
+
+	return locks, nil
+}
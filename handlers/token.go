@@ -1,7 +1,6 @@
 package handlers
 
 import (
-	"log"
 	"net/http"
 	"strconv"
 
@@ -9,23 +8,24 @@ import (
 	"mintscan/db"
 	"mintscan/errors"
 	"mintscan/utils"
+
+	"github.com/tendermint/tendermint/libs/log"
 )
 
 // Token is a token handler
 type Token struct {
-	l      *log.Logger
+	l      log.Logger
 	client *client.Client
 	db     *db.Database
 }
 
 // NewToken creates a new token handler with the given params
-func NewToken(l *log.Logger, client *client.Client, db *db.Database) *Token {
+func NewToken(l log.Logger, client *client.Client, db *db.Database) *Token {
 	return &Token{l, client, db}
 }
 
 // GetTokens returns assets based upon the request params
 func (t *Token) GetTokens(rw http.ResponseWriter, r *http.Request) {
-	rw.Header().Set("Access-Control-Allow-Origin", "*")
 	limit := 100
 	offset := 0
 
@@ -42,7 +42,7 @@ func (t *Token) GetTokens(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	tks, _ := t.client.Tokens(limit, offset)
+	tks, _ := t.client.WithContext(r.Context()).Tokens(limit, offset)
 
 	utils.Respond(rw, tks)
 	return
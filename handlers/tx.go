@@ -3,7 +3,6 @@ package handlers
 import (
 	"encoding/json"
 	"fmt"
-	"log"
 	"net/http"
 	"strconv"
 	"time"
@@ -16,23 +15,27 @@ import (
 	"mintscan/utils"
 
 	"github.com/gorilla/mux"
+
+	cmtypes "github.com/binance-chain/go-sdk/common/types"
+
+	"github.com/tendermint/tendermint/libs/log"
 )
 
 // Transaction is a transaction handler
 type Transaction struct {
-	l      *log.Logger
+	l      log.Logger
 	client *client.Client
 	db     *db.Database
+	nt     cmtypes.ChainNetwork
 }
 
 // NewTransaction creates a new transaction handler with the given params
-func NewTransaction(l *log.Logger, client *client.Client, db *db.Database) *Transaction {
-	return &Transaction{l, client, db}
+func NewTransaction(l log.Logger, client *client.Client, db *db.Database, network cmtypes.ChainNetwork) *Transaction {
+	return &Transaction{l, client, db, network}
 }
 
 // GetTxs returns transactions based upon the request params
 func (t *Transaction) GetTxs(rw http.ResponseWriter, r *http.Request) {
-	rw.Header().Set("Access-Control-Allow-Origin", "*")
 	before := int(0)
 	after := int(-1)
 	limit := int(100)
@@ -54,11 +57,11 @@ func (t *Transaction) GetTxs(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	txs, totalTxsNum := t.client.Txs(before, after, limit)
+	txs, totalTxsNum := t.client.WithContext(r.Context()).Txs(before, after, limit)
 
-	//txs, err := t.db.QueryTxs(before, after, limit)
+	//txs, err := t.db.WithContext(r.Context()).QueryTxs(before, after, limit)
 	//if err != nil {
-	//	t.l.Printf("failed to query txs: %s\n", err)
+	//	t.l.Error("failed to query txs", "err", err)
 	//}
 
 	if len(txs) <= 0 {
@@ -66,18 +69,20 @@ func (t *Transaction) GetTxs(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	setTxLabels(r.Context(), t.l, t.db, t.nt, txs)
+
 	result := &models.ResultTxs{
 		Data: txs,
 	}
 
 	//result, err := t.setTxs(txs)
 	//if err != nil {
-	//	t.l.Printf("failed to set txs: %s\n", err)
+	//	t.l.Error("failed to set txs", "err", err)
 	//}
 	//
-	//totalTxsNum, err := t.db.CountTotalTxsNum()
+	//totalTxsNum, err := t.db.WithContext(r.Context()).CountTotalTxsNum()
 	//if err != nil {
-	//	t.l.Printf("failed to query total number of txs: %s\n", err)
+	//	t.l.Error("failed to query total number of txs", "err", err)
 	//}
 
 	// Handling before and after since their ordering data is different
@@ -97,34 +102,36 @@ func (t *Transaction) GetTxs(rw http.ResponseWriter, r *http.Request) {
 
 // GetTxByHash returns certain transaction information by its tx hash
 func (t *Transaction) GetTxByHash(rw http.ResponseWriter, r *http.Request) {
-	rw.Header().Set("Access-Control-Allow-Origin", "*")
 	vars := mux.Vars(r)
 	hash := vars["hash"]
 
-	//tx, err := t.db.QueryTxByHash(hash)
+	//tx, err := t.db.WithContext(r.Context()).QueryTxByHash(hash)
 	//if err != nil {
-	//	t.l.Printf("failed to query tx: %s\n", err)
+	//	t.l.Error("failed to query tx", "err", err)
 	//	utils.Respond(rw, models.TxData{})
 	//	return
 	//}
 	//
 	//result, err := t.setTx(tx)
 	//if err != nil {
-	//	t.l.Printf("failed to set tx: %s\n", err)
+	//	t.l.Error("failed to set tx", "err", err)
 	//}
 
-	result, err := t.client.TxByHash(hash)
+	result, err := t.client.WithContext(r.Context()).TxByHash(hash)
 	if err != nil {
-		t.l.Printf("failed to set tx: %s\n", err)
+		t.l.Error("failed to set tx", "err", err)
 	}
 
+	txs := []models.TxData{result}
+	setTxLabels(r.Context(), t.l, t.db, t.nt, txs)
+	result = txs[0]
+
 	utils.Respond(rw, result)
 	return
 }
 
 // GetTxsByType returns transactions based upon the request params
 func (t *Transaction) GetTxsByType(rw http.ResponseWriter, r *http.Request) {
-	rw.Header().Set("Access-Control-Allow-Origin", "*")
 	before := int(0)
 	after := int(-1)
 	limit := int(100)
@@ -149,7 +156,7 @@ func (t *Transaction) GetTxsByType(rw http.ResponseWriter, r *http.Request) {
 	var txrp models.TxRequestPayload
 	err := json.NewDecoder(r.Body).Decode(&txrp)
 	if err != nil {
-		t.l.Printf("failed to decode txrp: %s\n", err)
+		t.l.Error("failed to decode txrp", "err", err)
 	}
 
 	// Set the first block time if StartTime is not parsed
@@ -170,22 +177,24 @@ func (t *Transaction) GetTxsByType(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	txs, totalTxsNum := t.client.TxsByTypeAndTime(txrp.TxType, txrp.StartTime, txrp.EndTime, before, after, limit)
+	txs, totalTxsNum := t.client.WithContext(r.Context()).TxsByTypeAndTime(txrp.TxType, txrp.StartTime, txrp.EndTime, before, after, limit)
 	if err != nil {
-		t.l.Printf("failed to query txs: %s\n", err)
+		t.l.Error("failed to query txs", "err", err)
 	}
 
 	if len(txs) <= 0 {
 		return
 	}
 
+	setTxLabels(r.Context(), t.l, t.db, t.nt, txs)
+
 	result := &models.ResultTxs{
 		Data: txs,
 	}
 
-	//totalTxsNum, err := t.db.CountTotalTxsNum()
+	//totalTxsNum, err := t.db.WithContext(r.Context()).CountTotalTxsNum()
 	//if err != nil {
-	//	t.l.Printf("failed to query total number of txs: %s\n", err)
+	//	t.l.Error("failed to query total number of txs", "err", err)
 	//}
 
 	// Handling before and after since their ordering data is different
@@ -1,44 +1,48 @@
 package handlers
 
 import (
-	"log"
-	"mintscan/schema"
+	"encoding/json"
 	"net/http"
+	"sort"
 	"time"
 
-	"github.com/gorilla/mux"
 	"mintscan/client"
 	"mintscan/db"
 	"mintscan/errors"
+	"mintscan/models"
+	"mintscan/schema"
 	"mintscan/utils"
 
+	"github.com/gorilla/mux"
+
 	cmtypes "github.com/binance-chain/go-sdk/common/types"
+
+	"github.com/tendermint/tendermint/libs/log"
 )
 
 // Validator is a validator handler
 type Validator struct {
-	l      *log.Logger
+	l      log.Logger
 	client *client.Client
 	db     *db.Database
 	nt     cmtypes.ChainNetwork
 }
 
 // NewValidator creates a new validator handler with the given params
-func NewValidator(l *log.Logger, client *client.Client, db *db.Database, network cmtypes.ChainNetwork) *Validator {
+func NewValidator(l log.Logger, client *client.Client, db *db.Database, network cmtypes.ChainNetwork) *Validator {
 	return &Validator{l, client, db, network}
 }
 
 // GetValidators returns validators on the active chain
 func (v *Validator) GetValidators(rw http.ResponseWriter, r *http.Request) {
-	rw.Header().Set("Access-Control-Allow-Origin", "*")
-	//vals, err := v.db.QueryValidators()
+	//vals, err := v.db.WithContext(r.Context()).QueryValidators()
 	//if err != nil {
-	//	v.l.Printf("failed to query validators: %s", err)
+	//	v.l.Error("failed to query validators", "err", err)
 	//	return
 	//}
-	tmpVals, err := v.client.Validators()
+	tmpVals, err := v.client.WithContext(r.Context()).Validators()
 	if err != nil {
-		v.l.Printf("failed to query validators: %s", err)
+		v.l.Error("failed to query validators", "err", err)
 		return
 	}
 
@@ -68,13 +72,27 @@ func (v *Validator) GetValidators(rw http.ResponseWriter, r *http.Request) {
 		vals = append(vals, val)
 	}
 
-	utils.Respond(rw, vals)
+	addresses := make([]string, 0)
+	for _, val := range vals {
+		addresses = append(addresses, val.AccountAddress)
+	}
+
+	labels := addressLabels(r.Context(), v.l, v.db, addresses...)
+
+	result := make([]models.ResultValidator, 0)
+	for _, val := range vals {
+		result = append(result, models.ResultValidator{
+			Validator: val,
+			Label:     labels[val.AccountAddress],
+		})
+	}
+
+	utils.Respond(rw, result)
 	return
 }
 
 // GetValidator returns validator information on the active chain
 func (v *Validator) GetValidator(rw http.ResponseWriter, r *http.Request) {
-	rw.Header().Set("Access-Control-Allow-Origin", "*")
 	vars := mux.Vars(r)
 	address := vars["address"]
 
@@ -83,9 +101,9 @@ func (v *Validator) GetValidator(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	val, err := v.client.Validator(address)
+	val, err := v.client.WithContext(r.Context()).Validator(address)
 	if err != nil {
-		v.l.Printf("failed to query validator by address: %s", err)
+		v.l.Error("failed to query validator by address", "err", err)
 		return
 	}
 
@@ -111,39 +129,189 @@ func (v *Validator) GetValidator(rw http.ResponseWriter, r *http.Request) {
 		Timestamp:               time.Now(),
 	}
 
-	utils.Respond(rw, validator)
+	utils.Respond(rw, &models.ResultValidator{
+		Validator: validator,
+		Label:     addressLabels(r.Context(), v.l, v.db, validator.AccountAddress)[validator.AccountAddress],
+	})
 	//switch {
 	//case strings.HasPrefix(address, v.nt.Bech32ValidatorAddrPrefix()):
-	//	result, err := v.db.QueryValidatorByOperAddr(address)
+	//	result, err := v.db.WithContext(r.Context()).QueryValidatorByOperAddr(address)
 	//	if err != nil {
-	//		v.l.Printf("failed to query validator by operator address: %s", err)
+	//		v.l.Error("failed to query validator by operator address", "err", err)
 	//		return
 	//	}
 	//	utils.Respond(rw, result)
 	//	return
 	//case strings.HasPrefix(address, v.nt.Bech32Prefixes()):
-	//	result, err := v.db.QueryValidatorByAccountAddr(address)
+	//	result, err := v.db.WithContext(r.Context()).QueryValidatorByAccountAddr(address)
 	//	if err != nil {
-	//		v.l.Printf("failed to query validator by account address: %s", err)
+	//		v.l.Error("failed to query validator by account address", "err", err)
 	//		return
 	//	}
 	//	utils.Respond(rw, result)
 	//	return
 	//case len(address) == 40:
-	//	result, err := v.db.QueryValidatorByConsAddr(address)
+	//	result, err := v.db.WithContext(r.Context()).QueryValidatorByConsAddr(address)
 	//	if err != nil {
-	//		v.l.Printf("failed to query validator by consensus address: %s", err)
+	//		v.l.Error("failed to query validator by consensus address", "err", err)
 	//		return
 	//	}
 	//	utils.Respond(rw, result)
 	//	return
 	//default:
-	//	result, err := v.db.QueryValidatorByMoniker(address)
+	//	result, err := v.db.WithContext(r.Context()).QueryValidatorByMoniker(address)
 	//	if err != nil {
-	//		v.l.Printf("failed to query validator by moniker: %s", err)
+	//		v.l.Error("failed to query validator by moniker", "err", err)
 	//		return
 	//	}
 	//	utils.Respond(rw, result)
 	//	return
 	//}
 }
+
+// GetValidatorEvents returns lifecycle events of a validator given its operator address.
+// Events are derived from indexed messages and validator snapshots in chronological order
+func (v *Validator) GetValidatorEvents(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	address := vars["address"]
+
+	if address == "" {
+		errors.ErrRequiredParam(rw, http.StatusBadRequest, "address is required")
+		return
+	}
+
+	if !utils.IsValAddress(v.nt, address) {
+		errors.ErrInvalidParam(rw, http.StatusBadRequest, "address must be an operator address")
+		return
+	}
+
+	filters := []string{
+		utils.MsgFilter("cosmos-sdk/MsgCreateValidator", map[string]string{"validator_address": address}),
+		utils.MsgFilter("cosmos-sdk/MsgCreateValidatorProposal", map[string]interface{}{
+			"MsgCreateValidator": map[string]string{"validator_address": address},
+		}),
+		utils.MsgFilter("cosmos-sdk/MsgRemoveValidator", map[string]string{"val_addr": address}),
+		utils.MsgFilter("cosmos-sdk/MsgEditValidator", map[string]string{"address": address}),
+		utils.MsgFilter("cosmos-sdk/MsgUnjail", map[string]string{"address": address}),
+	}
+
+	txs, err := v.db.WithContext(r.Context()).QueryAllTxsByMsgFilters(filters)
+	if err != nil {
+		v.l.Error("failed to query validator txs", "err", err)
+		errors.ErrInternalServer(rw, http.StatusInternalServerError)
+		return
+	}
+
+	events := make([]models.ValidatorEvent, 0)
+
+	// moniker and rate keep track of the latest known description and commission rate
+	// so that edit events can report the previous value
+	var moniker, rate string
+
+	for _, tx := range txs {
+		msgs := make([]models.Message, 0)
+		err := json.Unmarshal([]byte(tx.Messages), &msgs)
+		if err != nil {
+			v.l.Error("failed to unmarshal msgs", "err", err)
+			continue
+		}
+
+		for _, msg := range msgs {
+			event := models.ValidatorEvent{
+				Height:    tx.Height,
+				TxHash:    tx.TxHash,
+				Timestamp: tx.Timestamp,
+			}
+
+			switch msg.Type {
+			case "cosmos-sdk/MsgCreateValidator":
+				var value models.CreateValidatorMsgValue
+				if json.Unmarshal(msg.Value, &value) != nil || value.ValidatorAddress != address {
+					continue
+				}
+				event.Type = models.ValidatorEventCreate
+				event.New = value.Description.Moniker
+				moniker, rate = value.Description.Moniker, value.Commission.Rate
+			case "cosmos-sdk/MsgCreateValidatorProposal":
+				var value models.CreateValidatorProposalMsgValue
+				if json.Unmarshal(msg.Value, &value) != nil || value.MsgCreateValidator.ValidatorAddress != address {
+					continue
+				}
+				event.Type = models.ValidatorEventCreateProposal
+				event.ProposalID = value.ProposalID
+				event.New = value.MsgCreateValidator.Description.Moniker
+				moniker, rate = value.MsgCreateValidator.Description.Moniker, value.MsgCreateValidator.Commission.Rate
+			case "cosmos-sdk/MsgRemoveValidator":
+				var value models.RemoveValidatorMsgValue
+				if json.Unmarshal(msg.Value, &value) != nil || value.ValAddr != address {
+					continue
+				}
+				event.Type = models.ValidatorEventRemove
+				event.ProposalID = value.ProposalID
+			case "cosmos-sdk/MsgUnjail":
+				var value models.UnjailMsgValue
+				if json.Unmarshal(msg.Value, &value) != nil || value.ValidatorAddress != address {
+					continue
+				}
+				event.Type = models.ValidatorEventUnjail
+			case "cosmos-sdk/MsgEditValidator":
+				var value models.EditValidatorMsgValue
+				if json.Unmarshal(msg.Value, &value) != nil || value.ValidatorAddress != address {
+					continue
+				}
+
+				if value.CommissionRate != nil {
+					event.Type = models.ValidatorEventCommissionChange
+					event.Old = rate
+					event.New = *value.CommissionRate
+					events = append(events, event)
+					rate = *value.CommissionRate
+				}
+
+				if value.Description.Edited() {
+					event.Type = models.ValidatorEventDescriptionEdit
+					event.Old = moniker
+					event.New = moniker
+					if value.Description.Moniker != models.DoNotModifyDesc {
+						event.New = value.Description.Moniker
+					}
+					events = append(events, event)
+					moniker = event.New
+				}
+				continue
+			default:
+				continue
+			}
+
+			events = append(events, event)
+		}
+	}
+
+	// jailing happens without a tx, so it is detected from snapshots and its height is
+	// the height at which the change was observed
+	snapshots, err := v.db.WithContext(r.Context()).QueryValidatorSnapshots(address)
+	if err != nil {
+		v.l.Error("failed to query validator snapshots", "err", err)
+		errors.ErrInternalServer(rw, http.StatusInternalServerError)
+		return
+	}
+
+	for i := 1; i < len(snapshots); i++ {
+		prev, cur := snapshots[i-1], snapshots[i]
+
+		if !prev.Jailed && cur.Jailed {
+			events = append(events, models.ValidatorEvent{
+				Type:      models.ValidatorEventJail,
+				Height:    cur.Height,
+				Timestamp: cur.Timestamp,
+			})
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Height < events[j].Height
+	})
+
+	utils.Respond(rw, events)
+	return
+}
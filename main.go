@@ -2,73 +2,117 @@ package main
 
 import (
 	"context"
-	"log"
+	"flag"
+	"fmt"
+	stdlog "log"
 	"net/http"
 	"os"
 	"os/signal"
+	"sort"
+	"strings"
 	"time"
 
 	"mintscan/client"
 	"mintscan/config"
 	"mintscan/db"
+	"mintscan/exporter"
 	"mintscan/handlers"
+	"mintscan/metrics"
+	"mintscan/middleware"
+	"mintscan/tracing"
 
 	"github.com/pkg/errors"
 
 	"github.com/gorilla/mux"
+
+	"github.com/tendermint/tendermint/libs/log"
 )
 
 func main() {
-	l := log.New(os.Stdout, "Mintscan API ", log.Lshortfile|log.LstdFlags)
+	configPath := flag.String("config", "", "path to config file, defaults to $"+config.EnvPrefix+"_CONFIG or ./config.yaml")
+	flag.Parse()
 
-	cfg := config.ParseConfig()
+	cfg, err := config.ParseConfig(*configPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
 
-	client := client.NewClient(
-		cfg.Node,
-		cfg.Market,
-	)
+	l := newLogger(cfg.Log)
 
-	db := db.Connect(cfg.DB)
-	err := db.Ping()
+	shutdownTracing, err := tracing.Setup(cfg.Tracing)
 	if err != nil {
-		log.Fatal(errors.Wrap(err, "failed to ping database"))
+		l.Error("failed to set up tracing", "err", err)
+		os.Exit(1)
 	}
 
 	r := mux.NewRouter()
+	r.Use(middleware.RequestID, middleware.Logging(l.With("module", "http")), middleware.Compress)
 
-	getR := r.Methods(http.MethodGet).PathPrefix("/v1").Subrouter()
-	getR.HandleFunc("/account/{address}", handlers.NewAccount(l, client, db).GetAccount)
-	getR.HandleFunc("/account/txs/{address}", handlers.NewAccount(l, client, db).GetAccountTxs)
-	getR.HandleFunc("/asset", handlers.NewAsset(l, client, db).GetAsset)
-	getR.HandleFunc("/assets", handlers.NewAsset(l, client, db).GetAssets)
-	getR.HandleFunc("/assets/txs", handlers.NewAsset(l, client, db).GetAssetTxs)
-	getR.HandleFunc("/asset-holders", handlers.NewAsset(l, client, db).GetAssetHolders)
-	getR.HandleFunc("/assets-images", handlers.NewAsset(l, client, db).GetAssetsImages)
-	getR.HandleFunc("/blocks", handlers.NewBlock(l, client, db).GetBlocks)
-	getR.HandleFunc("/fees", handlers.NewFee(l, client, db).GetFees)
-	getR.HandleFunc("/validators", handlers.NewValidator(l, client, db, cfg.Node.NetworkType).GetValidators)
-	getR.HandleFunc("/validator/{address}", handlers.NewValidator(l, client, db, cfg.Node.NetworkType).GetValidator)
-	getR.HandleFunc("/market", handlers.NewMarket(l, client, db).GetCoinMarketData)
-	getR.HandleFunc("/market/chart", handlers.NewMarket(l, client, db).GetCoinMarketChartData)
-	getR.HandleFunc("/orders/{id}", handlers.NewOrder(l, client, db).GetOrders)
-	getR.HandleFunc("/stats/assets/chart", handlers.NewStatistic(l, client, db).GetAssetsChartHistory)
-	getR.HandleFunc("/status", handlers.NewStatus(l, client, db).GetStatus)
-	getR.HandleFunc("/tokens", handlers.NewToken(l, client, db).GetTokens)
-	getR.HandleFunc("/txs", handlers.NewTransaction(l, client, db).GetTxs)
-	getR.HandleFunc("/txs/{hash}", handlers.NewTransaction(l, client, db).GetTxByHash)
+	// Every network is served under /v1/{network} and under /v1 for its host names.
+	// The active network is served under /v1 for any other host, which is registered last
+	// so that routes of the other networks take precedence
+	names := make([]string, 0)
+	for name := range cfg.Networks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var active func(r *mux.Router)
+	targets := make([]handlers.HealthTarget, 0)
+
+	for _, name := range names {
+		netCfg := cfg.Networks[name]
+		nl := l.With("network", name)
 
-	postR := r.Methods(http.MethodPost).PathPrefix("/v1").Subrouter()
-	postR.HandleFunc("/txs", handlers.NewTransaction(l, client, db).GetTxsByType)
+		client, db := connect(nl, netCfg)
+		metrics.RegisterNetwork(name, db.DB, client.LastLatestBlockHeight, db.QueryLatestBlockHeight)
+
+		targets = append(targets, handlers.HealthTarget{
+			Network:         name,
+			Client:          client,
+			DB:              db,
+			StaleAfter:      netCfg.Health.StaleAfter,
+			Optional:        netCfg.Health.Optional,
+			OptionalNetwork: netCfg.Health.OptionalNetwork,
+		})
+		exporter.NewExporter(nl.With("module", "exporter"), client, db, netCfg.Asset).Start()
+
+		// Rate limit buckets are shared by every router of the network
+		rateLimit := middleware.RateLimit(nl.With("module", "ratelimit"), netCfg.RateLimit, db)
+
+		register := func(r *mux.Router) {
+			r.Use(middleware.Metrics(netCfg.Name), middleware.Tracing(netCfg.Name), rateLimit)
+			registerRoutes(r, nl.With("module", "handlers"), netCfg, client, db)
+		}
+
+		register(r.PathPrefix("/v1/" + name).Subrouter())
+
+		for _, host := range netCfg.Web.Hosts {
+			register(r.Host(host).PathPrefix("/v1").Subrouter())
+		}
+
+		if name == cfg.Active {
+			active = register
+		}
+	}
+
+	active(r.PathPrefix("/v1").Subrouter())
+
+	health := handlers.NewHealth(l.With("module", "health"), targets)
+	r.HandleFunc("/healthz", health.GetHealthz).Methods(http.MethodGet)
+	r.HandleFunc("/readyz", health.GetReadyz).Methods(http.MethodGet)
 
 	r.PathPrefix("/").HandlerFunc(func(w http.ResponseWriter, r *http.Request) { // catch-all
+		w.WriteHeader(http.StatusNotFound)
 		w.Write([]byte("No route is found matching the URL"))
 	})
 
 	// create a new server
 	sm := &http.Server{
 		Addr:         ":" + cfg.Web.Port,
-		Handler:      r,
-		ErrorLog:     l,
+		Handler:      middleware.SecurityHeaders(middleware.CORS(cfg.CORS)(r)),
+		ErrorLog:     stdlog.New(errorWriter{l.With("module", "http")}, "", 0),
 		ReadTimeout:  50 * time.Second,  // max time to read request from the client
 		WriteTimeout: 10 * time.Second,  // max time to write response to the client
 		IdleTimeout:  120 * time.Second, // max time for connections using TCP Keep-Alive
@@ -76,14 +120,40 @@ func main() {
 
 	// start the server
 	go func() {
-		l.Printf("Server is running on http://localhost:%s\n", cfg.Web.Port)
+		l.Info("server is running", "addr", "http://localhost:"+cfg.Web.Port)
 
 		err := sm.ListenAndServe()
 		if err != nil {
+			l.Error("failed to serve", "err", err)
 			os.Exit(1)
 		}
 	}()
 
+	// Metrics are served on their own port so that they are not exposed with the API
+	var msm *http.Server
+	if cfg.Web.MetricsPort != "" {
+		mr := http.NewServeMux()
+		mr.Handle("/metrics", metrics.Handler())
+
+		msm = &http.Server{
+			Addr:         ":" + cfg.Web.MetricsPort,
+			Handler:      mr,
+			ErrorLog:     stdlog.New(errorWriter{l.With("module", "metrics")}, "", 0),
+			ReadTimeout:  10 * time.Second,
+			WriteTimeout: 30 * time.Second,
+		}
+
+		go func() {
+			l.Info("metrics server is running", "addr", "http://localhost:"+cfg.Web.MetricsPort+"/metrics")
+
+			err := msm.ListenAndServe()
+			if err != nil && err != http.ErrServerClosed {
+				l.Error("failed to serve metrics", "err", err)
+				os.Exit(1)
+			}
+		}()
+	}
+
 	// trap sigterm or interupt and gracefully shutdown the server
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt)
@@ -96,6 +166,135 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 	sm.Shutdown(ctx)
+	if msm != nil {
+		msm.Shutdown(ctx)
+	}
+	shutdownTracing(ctx)
+
+	l.Info("gracefully shutting down the server", "signal", sig)
+}
+
+// newLogger creates a logger that writes to stdout in the configured format and filters by the configured level
+func newLogger(cfg config.LogConfig) log.Logger {
+	var l log.Logger
+	switch cfg.Format {
+	case "json":
+		l = log.NewTMJSONLogger(log.NewSyncWriter(os.Stdout))
+	default:
+		l = log.NewTMLogger(log.NewSyncWriter(os.Stdout))
+	}
+
+	option, err := log.AllowLevel(cfg.Level)
+	if err != nil {
+		option = log.AllowInfo()
+	}
+
+	return log.NewFilter(l, option)
+}
+
+// errorWriter writes errors of http server to a logger
+type errorWriter struct {
+	l log.Logger
+}
+
+// Write implements io.Writer interface
+func (w errorWriter) Write(p []byte) (int, error) {
+	w.l.Error("http server error", "err", strings.TrimSpace(string(p)))
+	return len(p), nil
+}
+
+// connect creates a client and a database connection of a network and checks both of them.
+// Tables managed by this service are created if they do not exist
+func connect(l log.Logger, cfg *config.NetworkConfig) (*client.Client, *db.Database) {
+	fatal := func(err error) {
+		l.Error(err.Error())
+		os.Exit(1)
+	}
+
+	client := client.NewClient(
+		l.With("module", "client"),
+		cfg.Name,
+		cfg.Node,
+		cfg.Market,
+	)
+
+	err := client.CheckChainID(cfg.Node.ChainID)
+	if err != nil {
+		fatal(errors.Wrapf(err, "failed to check chain id of %s", cfg.Name))
+	}
+
+	db := db.Connect(cfg.DB)
+	err = db.Ping()
+	if err != nil {
+		fatal(errors.Wrapf(err, "failed to ping database of %s", cfg.Name))
+	}
+
+	err = db.CreateTables()
+	if err != nil {
+		fatal(errors.Wrapf(err, "failed to create tables of %s", cfg.Name))
+	}
+
+	return client, db
+}
+
+// registerRoutes registers routes of a network on the given router
+func registerRoutes(r *mux.Router, l log.Logger, cfg *config.NetworkConfig, client *client.Client, db *db.Database) {
+	getR := r.Methods(http.MethodGet).Subrouter()
+	getR.Use(middleware.ETag)
+	getR.HandleFunc("/account/{address}", handlers.NewAccount(l, client, db, cfg.Node.NetworkType).GetAccount)
+	getR.HandleFunc("/account/txs/{address}", handlers.NewAccount(l, client, db, cfg.Node.NetworkType).GetAccountTxs)
+	getR.HandleFunc("/account/{address}/orders", handlers.NewOrder(l, client, db, cfg.Node.NetworkType).GetAccountOrders)
+	getR.HandleFunc("/account/{address}/swaps", handlers.NewSwap(l, client, db, cfg.Node.NetworkType).GetAccountSwaps)
+	getR.HandleFunc("/account/{address}/flags", handlers.NewAccount(l, client, db, cfg.Node.NetworkType).GetAccountFlags)
+	getR.HandleFunc("/account/{address}/timelocks", handlers.NewAccount(l, client, db, cfg.Node.NetworkType).GetAccountTimeLocks)
+	getR.HandleFunc("/accounts/top", handlers.NewAccount(l, client, db, cfg.Node.NetworkType).GetTopAccounts)
+	getR.HandleFunc("/asset", handlers.NewAsset(l, client, db).GetAsset)
+	getR.HandleFunc("/assets", handlers.NewAsset(l, client, db).GetAssets)
+	getR.HandleFunc("/assets/txs", handlers.NewAsset(l, client, db).GetAssetTxs)
+	getR.HandleFunc("/assets/{asset}/distribution", handlers.NewAsset(l, client, db).GetAssetDistribution)
+	getR.HandleFunc("/assets/{asset}/supply-events", handlers.NewAsset(l, client, db).GetAssetSupplyEvents)
+	getR.HandleFunc("/asset-holders", handlers.NewAsset(l, client, db).GetAssetHolders)
+	getR.HandleFunc("/assets-images", handlers.NewAsset(l, client, db).GetAssetsImages)
+	getR.HandleFunc("/blocks", handlers.NewBlock(l, client, db, cfg.Node.NetworkType).GetBlocks)
+	getR.HandleFunc("/blocks/{height:[0-9]+}", handlers.NewBlock(l, client, db, cfg.Node.NetworkType).GetBlock)
+	getR.HandleFunc("/blocks/hash/{hash}", handlers.NewBlock(l, client, db, cfg.Node.NetworkType).GetBlockByHash)
+	getR.HandleFunc("/fees", handlers.NewFee(l, client, db).GetFees)
+	getR.HandleFunc("/validators", handlers.NewValidator(l, client, db, cfg.Node.NetworkType).GetValidators)
+	getR.HandleFunc("/validator/{address}", handlers.NewValidator(l, client, db, cfg.Node.NetworkType).GetValidator)
+	getR.HandleFunc("/validator/{address}/events", handlers.NewValidator(l, client, db, cfg.Node.NetworkType).GetValidatorEvents)
+	getR.HandleFunc("/market", handlers.NewMarket(l, client, db).GetCoinMarketData)
+	getR.HandleFunc("/market/chart", handlers.NewMarket(l, client, db).GetCoinMarketChartData)
+	getR.HandleFunc("/orders/{id}", handlers.NewOrder(l, client, db, cfg.Node.NetworkType).GetOrders)
+	getR.HandleFunc("/stats/assets/chart", handlers.NewStatistic(l, client, db).WithFeaturedAssets(cfg.Asset.FeaturedAssets).GetAssetsChartHistory)
+	getR.HandleFunc("/stats/candles", handlers.NewStatistic(l, client, db).GetCandles)
+	getR.HandleFunc("/stats/network", handlers.NewStatistic(l, client, db).GetNetworkStats)
+	getR.HandleFunc("/proposals", handlers.NewProposal(l, client, db).GetProposals)
+	getR.HandleFunc("/proposals/{id}", handlers.NewProposal(l, client, db).GetProposal)
+	getR.HandleFunc("/proposals/{id}/votes", handlers.NewProposal(l, client, db).GetProposalVotes)
+	getR.HandleFunc("/proposals/{id}/deposits", handlers.NewProposal(l, client, db).GetProposalDeposits)
+	getR.HandleFunc("/search", handlers.NewSearch(l, client, db, cfg.Node.NetworkType).GetSearch)
+	getR.HandleFunc("/status", handlers.NewStatus(l, client, db, cfg.Status.BlockTimeWindow).GetStatus)
+	getR.HandleFunc("/swaps", handlers.NewSwap(l, client, db, cfg.Node.NetworkType).GetSwaps)
+	getR.HandleFunc("/swaps/{id}", handlers.NewSwap(l, client, db, cfg.Node.NetworkType).GetSwap)
+	getR.HandleFunc("/tokens", handlers.NewToken(l, client, db).GetTokens)
+	getR.HandleFunc("/txs", handlers.NewTransaction(l, client, db, cfg.Node.NetworkType).GetTxs)
+	getR.HandleFunc("/txs/{hash}", handlers.NewTransaction(l, client, db, cfg.Node.NetworkType).GetTxByHash)
+
+	postR := r.Methods(http.MethodPost).Subrouter()
+	postR.HandleFunc("/txs", handlers.NewTransaction(l, client, db, cfg.Node.NetworkType).GetTxsByType)
+
+	tiers := make([]string, 0)
+	for tier := range cfg.RateLimit.Tiers {
+		tiers = append(tiers, tier)
+	}
+	sort.Strings(tiers)
 
-	l.Println("Gracefully shutting down the server: ", sig)
+	adminR := r.PathPrefix("/admin").Subrouter()
+	adminR.HandleFunc("/featured-assets", handlers.NewAdmin(l, client, db, cfg.Node.NetworkType, cfg.Web.AdminToken, tiers).GetFeaturedAssets).Methods(http.MethodGet)
+	adminR.HandleFunc("/featured-assets", handlers.NewAdmin(l, client, db, cfg.Node.NetworkType, cfg.Web.AdminToken, tiers).PutFeaturedAssets).Methods(http.MethodPut)
+	adminR.HandleFunc("/labels", handlers.NewAdmin(l, client, db, cfg.Node.NetworkType, cfg.Web.AdminToken, tiers).GetLabels).Methods(http.MethodGet)
+	adminR.HandleFunc("/labels", handlers.NewAdmin(l, client, db, cfg.Node.NetworkType, cfg.Web.AdminToken, tiers).PostLabels).Methods(http.MethodPost)
+	adminR.HandleFunc("/api-keys", handlers.NewAdmin(l, client, db, cfg.Node.NetworkType, cfg.Web.AdminToken, tiers).GetAPIKeys).Methods(http.MethodGet)
+	adminR.HandleFunc("/api-keys", handlers.NewAdmin(l, client, db, cfg.Node.NetworkType, cfg.Web.AdminToken, tiers).PostAPIKey).Methods(http.MethodPost)
+	adminR.HandleFunc("/api-keys/{id:[0-9]+}", handlers.NewAdmin(l, client, db, cfg.Node.NetworkType, cfg.Web.AdminToken, tiers).DeleteAPIKey).Methods(http.MethodDelete)
 }
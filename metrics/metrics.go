@@ -0,0 +1,151 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-pg/pg"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Namespace prefixes names of all metrics exposed by this service
+const Namespace = "mintscan"
+
+var (
+	httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "Latency of HTTP requests by route template and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"network", "route", "method", "status"})
+
+	upstreamCalls = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "upstream",
+		Name:      "calls_total",
+		Help:      "Number of upstream calls by client method and result.",
+	}, []string{"network", "call", "result"})
+
+	upstreamCallDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Subsystem: "upstream",
+		Name:      "call_duration_seconds",
+		Help:      "Latency of upstream calls by client method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"network", "call"})
+)
+
+func init() {
+	prometheus.MustRegister(httpRequestDuration, upstreamCalls, upstreamCallDuration)
+}
+
+// Handler returns a handler that exposes all registered metrics
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// ObserveHTTPRequest records a served HTTP request
+func ObserveHTTPRequest(network string, route string, method string, status int, duration time.Duration) {
+	httpRequestDuration.WithLabelValues(network, route, method, strconv.Itoa(status)).Observe(duration.Seconds())
+}
+
+// ObserveUpstreamCall records an upstream call made by a client method
+func ObserveUpstreamCall(network string, call string, duration time.Duration, err error) {
+	result := "ok"
+	if err != nil {
+		result = "error"
+	}
+
+	upstreamCalls.WithLabelValues(network, call, result).Inc()
+	upstreamCallDuration.WithLabelValues(network, call).Observe(duration.Seconds())
+}
+
+// RegisterNetwork registers a collector of database connection pool stats and indexer progress of a network.
+// Indexer progress is read on every scrape from the given functions that return the latest block height
+// of the chain, which should be known without requesting the node, and the latest indexed block height
+func RegisterNetwork(network string, db *pg.DB, latestHeight, indexedHeight func() (int64, error)) {
+	prometheus.MustRegister(&networkCollector{
+		db:            db,
+		latestHeight:  latestHeight,
+		indexedHeight: indexedHeight,
+		descs:         newNetworkDescs(network),
+	})
+}
+
+// networkDescs describes metrics collected by networkCollector
+type networkDescs struct {
+	poolHits, poolMisses, poolTimeouts            *prometheus.Desc
+	poolTotalConns, poolIdleConns, poolStaleConns *prometheus.Desc
+	indexedHeight, indexerLag                     *prometheus.Desc
+}
+
+func newNetworkDescs(network string) networkDescs {
+	desc := func(subsystem, name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, name),
+			help,
+			nil,
+			prometheus.Labels{"network": network},
+		)
+	}
+
+	return networkDescs{
+		poolHits:       desc("db_pool", "hits_total", "Number of times a free connection was found in the pool."),
+		poolMisses:     desc("db_pool", "misses_total", "Number of times a free connection was not found in the pool."),
+		poolTimeouts:   desc("db_pool", "timeouts_total", "Number of times a wait timeout occurred."),
+		poolTotalConns: desc("db_pool", "total_conns", "Number of total connections in the pool."),
+		poolIdleConns:  desc("db_pool", "idle_conns", "Number of idle connections in the pool."),
+		poolStaleConns: desc("db_pool", "stale_conns_total", "Number of stale connections removed from the pool."),
+		indexedHeight:  desc("indexer", "block_height", "Latest indexed block height."),
+		indexerLag:     desc("indexer", "lag_blocks", "Number of blocks the indexer is behind the chain."),
+	}
+}
+
+// networkCollector collects metrics of a network on every scrape
+type networkCollector struct {
+	db            *pg.DB
+	latestHeight  func() (int64, error)
+	indexedHeight func() (int64, error)
+	descs         networkDescs
+}
+
+// Describe implements prometheus.Collector interface
+func (c *networkCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.descs.poolHits
+	ch <- c.descs.poolMisses
+	ch <- c.descs.poolTimeouts
+	ch <- c.descs.poolTotalConns
+	ch <- c.descs.poolIdleConns
+	ch <- c.descs.poolStaleConns
+	ch <- c.descs.indexedHeight
+	ch <- c.descs.indexerLag
+}
+
+// Collect implements prometheus.Collector interface. Indexer metrics are left out when heights are not available
+func (c *networkCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.db.PoolStats()
+	ch <- prometheus.MustNewConstMetric(c.descs.poolHits, prometheus.CounterValue, float64(stats.Hits))
+	ch <- prometheus.MustNewConstMetric(c.descs.poolMisses, prometheus.CounterValue, float64(stats.Misses))
+	ch <- prometheus.MustNewConstMetric(c.descs.poolTimeouts, prometheus.CounterValue, float64(stats.Timeouts))
+	ch <- prometheus.MustNewConstMetric(c.descs.poolTotalConns, prometheus.GaugeValue, float64(stats.TotalConns))
+	ch <- prometheus.MustNewConstMetric(c.descs.poolIdleConns, prometheus.GaugeValue, float64(stats.IdleConns))
+	ch <- prometheus.MustNewConstMetric(c.descs.poolStaleConns, prometheus.CounterValue, float64(stats.StaleConns))
+
+	indexed, err := c.indexedHeight()
+	if err != nil || indexed <= 0 {
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.descs.indexedHeight, prometheus.GaugeValue, float64(indexed))
+
+	latest, err := c.latestHeight()
+	if err != nil || latest <= 0 {
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.descs.indexerLag, prometheus.GaugeValue, float64(latest-indexed))
+}
@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+)
+
+// Compress compresses responses with brotli or gzip, whichever the client accepts, preferring brotli.
+// Responses that are already encoded, such as metrics, and responses without a body are left as is
+func Compress(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Add("Vary", "Accept-Encoding")
+
+		encoding := acceptedEncoding(r.Header.Get("Accept-Encoding"))
+		if encoding == "" || r.Method == http.MethodHead {
+			next.ServeHTTP(rw, r)
+			return
+		}
+
+		cw := &compressWriter{ResponseWriter: rw, encoding: encoding}
+		defer cw.Close()
+
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// acceptedEncoding returns the preferred encoding of the given Accept-Encoding header,
+// or an empty string when neither brotli nor gzip is accepted
+func acceptedEncoding(header string) string {
+	accepted := make(map[string]bool)
+	for _, part := range strings.Split(header, ",") {
+		params := strings.Split(part, ";")
+		name := strings.ToLower(strings.TrimSpace(params[0]))
+
+		q := 1.0
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if v, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+
+		accepted[name] = q > 0
+	}
+
+	switch {
+	case accepted["br"]:
+		return "br"
+	case accepted["gzip"]:
+		return "gzip"
+	}
+
+	return ""
+}
+
+// compressWriter compresses the body written to a response, deciding whether to compress
+// when the header is written
+type compressWriter struct {
+	http.ResponseWriter
+	encoding    string
+	w           io.WriteCloser // nil when the response is not compressed
+	wroteHeader bool
+}
+
+// WriteHeader implements http.ResponseWriter interface
+func (w *compressWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+
+	h := w.Header()
+	if h.Get("Content-Encoding") == "" && status != http.StatusNoContent && status != http.StatusNotModified {
+		h.Set("Content-Encoding", w.encoding)
+		h.Del("Content-Length")
+
+		if w.encoding == "br" {
+			w.w = brotli.NewWriter(w.ResponseWriter)
+		} else {
+			w.w = gzip.NewWriter(w.ResponseWriter)
+		}
+	}
+
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write implements http.ResponseWriter interface
+func (w *compressWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		if w.Header().Get("Content-Type") == "" {
+			w.Header().Set("Content-Type", http.DetectContentType(p))
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+
+	if w.w == nil {
+		return w.ResponseWriter.Write(p)
+	}
+
+	return w.w.Write(p)
+}
+
+// Close flushes the compressed body
+func (w *compressWriter) Close() error {
+	if w.w == nil {
+		return nil
+	}
+
+	return w.w.Close()
+}
@@ -0,0 +1,28 @@
+package middleware
+
+import "testing"
+
+func TestAcceptedEncoding(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   string
+	}{
+		{"empty", "", ""},
+		{"gzip", "gzip", "gzip"},
+		{"brotli is preferred", "gzip, deflate, br", "br"},
+		{"case insensitive", "GZIP", "gzip"},
+		{"brotli refused", "br;q=0, gzip", "gzip"},
+		{"gzip refused", "gzip; q=0", ""},
+		{"weighted", "gzip;q=0.5, br;q=0.1", "br"},
+		{"unsupported", "deflate, identity", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := acceptedEncoding(tt.header); got != tt.want {
+				t.Errorf("acceptedEncoding(%q) = %q, want %q", tt.header, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"mintscan/config"
+)
+
+// corsExposedHeaders are response headers that browsers let cross-origin scripts read
+var corsExposedHeaders = []string{
+	RequestIDHeader,
+	"ETag",
+	"Retry-After",
+	"X-RateLimit-Limit",
+	"X-RateLimit-Remaining",
+	"X-RateLimit-Reset",
+}
+
+// CORS allows cross-origin requests from the configured origins and answers preflight requests.
+// It wraps the whole router rather than being a route middleware, since the router rejects
+// OPTIONS requests to routes that only accept other methods before any route middleware runs
+func CORS(cfg config.CORSConfig) func(http.Handler) http.Handler {
+	anyOrigin := false
+	origins := make(map[string]bool)
+	for _, origin := range cfg.AllowedOrigins {
+		if origin == "*" {
+			anyOrigin = true
+		}
+		origins[strings.ToLower(origin)] = true
+	}
+
+	methods := strings.Join(cfg.AllowedMethods, ", ")
+	headers := strings.Join(cfg.AllowedHeaders, ", ")
+	exposed := strings.Join(corsExposedHeaders, ", ")
+	maxAge := strconv.Itoa(int(cfg.MaxAge.Seconds()))
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			if !anyOrigin {
+				rw.Header().Add("Vary", "Origin")
+			}
+
+			if origin == "" {
+				next.ServeHTTP(rw, r)
+				return
+			}
+
+			switch {
+			case anyOrigin:
+				rw.Header().Set("Access-Control-Allow-Origin", "*")
+			case origins[strings.ToLower(origin)]:
+				rw.Header().Set("Access-Control-Allow-Origin", origin)
+			default:
+				next.ServeHTTP(rw, r)
+				return
+			}
+
+			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+				rw.Header().Add("Vary", "Access-Control-Request-Method")
+				rw.Header().Add("Vary", "Access-Control-Request-Headers")
+				rw.Header().Set("Access-Control-Allow-Methods", methods)
+				rw.Header().Set("Access-Control-Allow-Headers", headers)
+				rw.Header().Set("Access-Control-Max-Age", maxAge)
+				rw.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			rw.Header().Set("Access-Control-Expose-Headers", exposed)
+			next.ServeHTTP(rw, r)
+		})
+	}
+}
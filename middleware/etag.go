@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+// ETag tags successful GET responses with a hash of the body and responds 304 without the body
+// when the tag matches If-None-Match of the request. Responses marked with Cache-Control: no-store
+// are not tagged. The body is buffered to compute the hash, so this should only wrap cacheable
+// routes with bounded responses
+func ETag(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			next.ServeHTTP(rw, r)
+			return
+		}
+
+		bw := &bufferWriter{header: make(http.Header), status: http.StatusOK}
+		next.ServeHTTP(bw, r)
+
+		h := rw.Header()
+		for key, values := range bw.header {
+			h[key] = values
+		}
+
+		if bw.status != http.StatusOK || h.Get("ETag") != "" || strings.Contains(h.Get("Cache-Control"), "no-store") {
+			rw.WriteHeader(bw.status)
+			rw.Write(bw.body.Bytes())
+			return
+		}
+
+		sum := sha256.Sum256(bw.body.Bytes())
+		etag := `W/"` + hex.EncodeToString(sum[:16]) + `"`
+		h.Set("ETag", etag)
+
+		if etagMatches(r.Header.Get("If-None-Match"), etag) {
+			h.Del("Content-Type")
+			h.Del("Content-Length")
+			rw.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		rw.WriteHeader(bw.status)
+		rw.Write(bw.body.Bytes())
+	})
+}
+
+// etagMatches reports whether the given If-None-Match header matches the tag,
+// comparing tags weakly as required for If-None-Match
+func etagMatches(header string, etag string) bool {
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+
+	for _, tag := range strings.Split(header, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(tag), "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// bufferWriter buffers a response so that it can be inspected before it is written
+type bufferWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+	wrote  bool
+}
+
+// Header implements http.ResponseWriter interface
+func (w *bufferWriter) Header() http.Header {
+	return w.header
+}
+
+// WriteHeader implements http.ResponseWriter interface
+func (w *bufferWriter) WriteHeader(status int) {
+	if w.wrote {
+		return
+	}
+	w.wrote = true
+	w.status = status
+}
+
+// Write implements http.ResponseWriter interface
+func (w *bufferWriter) Write(p []byte) (int, error) {
+	w.wrote = true
+	return w.body.Write(p)
+}
@@ -0,0 +1,31 @@
+package middleware
+
+import "testing"
+
+func TestETagMatches(t *testing.T) {
+	etag := `W/"abc"`
+
+	tests := []struct {
+		name   string
+		header string
+		want   bool
+	}{
+		{"empty", "", false},
+		{"wildcard", "*", true},
+		{"wildcard with spaces", " * ", true},
+		{"weak tag", `W/"abc"`, true},
+		{"strong tag compared weakly", `"abc"`, true},
+		{"one of tags", `"xyz", W/"abc"`, true},
+		{"no spaces between tags", `"xyz",W/"abc"`, true},
+		{"different tag", `W/"abd"`, false},
+		{"unquoted tag", `abc`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := etagMatches(tt.header, etag); got != tt.want {
+				t.Errorf("etagMatches(%q, %q) = %v, want %v", tt.header, etag, got, tt.want)
+			}
+		})
+	}
+}
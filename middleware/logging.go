@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"mintscan/client"
+
+	"github.com/gorilla/mux"
+	"github.com/tendermint/tendermint/libs/log"
+)
+
+// RequestIDHeader is the header that carries request id
+const RequestIDHeader = "X-Request-ID"
+
+type contextKey int
+
+const requestIDKey contextKey = iota
+
+// RequestID propagates request id of incoming requests, or assigns a new one when they have none.
+// Request id is echoed in response header and is available through RequestIDFromContext
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if id == "" || len(id) > 128 {
+			id = newRequestID()
+		}
+
+		rw.Header().Set(RequestIDHeader, id)
+		next.ServeHTTP(rw, r.WithContext(context.WithValue(r.Context(), requestIDKey, id)))
+	})
+}
+
+// RequestIDFromContext returns request id of a request context, or an empty string when it has none
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Logging logs every request with its method, route template, status, latency and
+// upstream calls made through client.Client with the request context
+func Logging(l log.Logger) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			ctx, calls := client.WithCallLog(r.Context())
+			sw := &statusWriter{ResponseWriter: rw, status: http.StatusOK}
+			next.ServeHTTP(sw, r.WithContext(ctx))
+
+			upstream := make([]string, 0)
+			for _, call := range calls.Calls() {
+				status := "ok"
+				if call.Err != nil {
+					status = "error"
+				}
+				upstream = append(upstream, call.Method+":"+status+":"+call.Duration.Round(time.Microsecond).String())
+			}
+
+			keyvals := []interface{}{
+				"request_id", RequestIDFromContext(r.Context()),
+				"method", r.Method,
+				"route", routeTemplate(r),
+				"status", sw.status,
+				"latency", time.Since(start),
+				"upstream", strings.Join(upstream, ","),
+			}
+
+			if sw.status >= http.StatusInternalServerError {
+				l.Error("request", keyvals...)
+				return
+			}
+
+			l.Info("request", keyvals...)
+		})
+	}
+}
+
+// routeTemplate returns path template of the route that matched a request, or its path when none matched
+func routeTemplate(r *http.Request) string {
+	if route := mux.CurrentRoute(r); route != nil {
+		if tpl, err := route.GetPathTemplate(); err == nil {
+			return tpl
+		}
+	}
+
+	return r.URL.Path
+}
+
+// statusWriter records status code written to a response
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader implements http.ResponseWriter interface
+func (w *statusWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
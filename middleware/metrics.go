@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"mintscan/metrics"
+
+	"github.com/gorilla/mux"
+)
+
+// Metrics records latency of every request served by routes of the named network,
+// labeled by route template, method and status code
+func Metrics(network string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			sw := &statusWriter{ResponseWriter: rw, status: http.StatusOK}
+			next.ServeHTTP(sw, r)
+
+			metrics.ObserveHTTPRequest(network, routeTemplate(r), r.Method, sw.status, time.Since(start))
+		})
+	}
+}
@@ -0,0 +1,286 @@
+package middleware
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"mintscan/config"
+	"mintscan/db"
+	"mintscan/errors"
+	"mintscan/schema"
+	"mintscan/utils"
+
+	"github.com/gorilla/mux"
+	"github.com/tendermint/tendermint/libs/log"
+)
+
+// APIKeyHeader is the header that carries API key
+const APIKeyHeader = "X-API-Key"
+
+// apiKeyTTL is how long API keys looked up in database are cached, which is also
+// how long it takes for a disabled key to stop working
+const apiKeyTTL = time.Minute
+
+// maxMissingAPIKeys bounds the number of cached lookups of API keys that don't exist
+const maxMissingAPIKeys = 10000
+
+// RateLimit limits requests by token buckets. Requests with an API key saved in database are limited per key
+// by the tier of the key, and the other requests are limited per client IP. Every limited response carries
+// X-RateLimit-Limit, X-RateLimit-Remaining and X-RateLimit-Reset headers, the last of which is the number of
+// seconds until the bucket is full again. Requests over the limit are rejected with 429. Requests whose API key
+// has to be looked up in database or turns out to be invalid take a token from the bucket of the client IP,
+// so that random keys can't be used to get around the limit or to flood the database.
+// Buckets are kept by the returned middleware, so the same middleware should be used for every route of a network
+func RateLimit(l log.Logger, cfg config.RateLimitConfig, db *db.Database) mux.MiddlewareFunc {
+	rl := &rateLimiter{
+		l:       l,
+		cfg:     cfg,
+		db:      db,
+		buckets: make(map[string]*bucket),
+		keys:    make(map[string]cachedAPIKey),
+	}
+
+	go rl.sweep()
+
+	return rl.middleware
+}
+
+// rateLimiter keeps token buckets and cached API keys
+type rateLimiter struct {
+	l   log.Logger
+	cfg config.RateLimitConfig
+	db  *db.Database
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	keys    map[string]cachedAPIKey // keyed by hash of API key
+	missing int                     // number of cached keys that don't exist
+}
+
+// bucket is a token bucket
+type bucket struct {
+	tier   config.RateLimitTier
+	tokens float64
+	last   time.Time
+}
+
+// cachedAPIKey is an API key looked up in database, which is nil when the key doesn't exist
+type cachedAPIKey struct {
+	key     *schema.APIKey
+	expires time.Time
+}
+
+func (rl *rateLimiter) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		ipKey := "ip:" + rl.clientIP(r)
+
+		apiKey := r.Header.Get(APIKeyHeader)
+		if apiKey == "" {
+			if rl.limit(rw, ipKey, rl.cfg.IP) {
+				next.ServeHTTP(rw, r)
+			}
+			return
+		}
+
+		hash := utils.HashAPIKey(apiKey)
+		key, ok := rl.cachedAPIKey(hash)
+		chargedIP := false
+
+		if !ok {
+			if !rl.limit(rw, ipKey, rl.cfg.IP) {
+				return
+			}
+			chargedIP = true
+
+			var err error
+			key, err = rl.apiKey(r, hash)
+			if err != nil {
+				rl.l.Error("failed to query api key", "err", err)
+				errors.ErrInternalServer(rw, http.StatusInternalServerError)
+				return
+			}
+		}
+
+		if key == nil || key.Disabled {
+			if chargedIP || rl.limit(rw, ipKey, rl.cfg.IP) {
+				errors.ErrInvalidAPIKey(rw, http.StatusUnauthorized)
+			}
+			return
+		}
+
+		bucketKey, tier := ipKey, rl.cfg.IP
+		if keyTier, ok := rl.cfg.Tiers[key.Tier]; ok {
+			bucketKey, tier = "key:"+strconv.Itoa(int(key.ID)), keyTier
+		} else {
+			rl.l.Error("api key has unknown tier, limiting by ip", "id", key.ID, "tier", key.Tier)
+		}
+
+		// The IP bucket is taken from once per request
+		if chargedIP && bucketKey == ipKey {
+			next.ServeHTTP(rw, r)
+			return
+		}
+
+		if rl.limit(rw, bucketKey, tier) {
+			next.ServeHTTP(rw, r)
+		}
+	})
+}
+
+// limit takes a token from the bucket of the given key and sets rate limit headers. It responds 429 and
+// returns false when the bucket is empty. Requests are always allowed when the rate of the tier is 0
+func (rl *rateLimiter) limit(rw http.ResponseWriter, key string, tier config.RateLimitTier) bool {
+	if tier.Rate <= 0 {
+		return true
+	}
+
+	allowed, remaining, reset, retryAfter := rl.take(key, tier, time.Now())
+
+	rw.Header().Set("X-RateLimit-Limit", strconv.Itoa(tier.Burst))
+	rw.Header().Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+	rw.Header().Set("X-RateLimit-Reset", strconv.Itoa(seconds(reset)))
+
+	if !allowed {
+		rw.Header().Set("Retry-After", strconv.Itoa(seconds(retryAfter)))
+		errors.ErrTooManyRequests(rw, http.StatusTooManyRequests)
+		return false
+	}
+
+	return true
+}
+
+// take takes a token from the bucket of the given key. It returns whether a token was taken, the number of
+// remaining tokens, the time until the bucket is full and the time until a token is available
+func (rl *rateLimiter) take(key string, tier config.RateLimitTier, now time.Time) (bool, int, time.Duration, time.Duration) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	b, ok := rl.buckets[key]
+	if !ok || b.tier != tier {
+		b = &bucket{tier: tier, tokens: float64(tier.Burst), last: now}
+		rl.buckets[key] = b
+	}
+
+	b.tokens = math.Min(float64(tier.Burst), b.tokens+now.Sub(b.last).Seconds()*tier.Rate)
+	b.last = now
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+
+	reset := refill(float64(tier.Burst)-b.tokens, tier.Rate)
+	retryAfter := refill(1-b.tokens, tier.Rate)
+
+	return allowed, int(b.tokens), reset, retryAfter
+}
+
+// sweep periodically removes buckets that have been refilled, which are the same as new buckets
+func (rl *rateLimiter) sweep() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for now := range ticker.C {
+		rl.mu.Lock()
+		for key, b := range rl.buckets {
+			if now.Sub(b.last) >= refill(float64(b.tier.Burst)-b.tokens, b.tier.Rate) {
+				delete(rl.buckets, key)
+			}
+		}
+
+		for hash, cached := range rl.keys {
+			if now.After(cached.expires) {
+				rl.deleteAPIKey(hash)
+			}
+		}
+		rl.mu.Unlock()
+	}
+}
+
+// cachedAPIKey returns the cached API key of the given hash, which is nil when the key doesn't exist.
+// It returns false when the key is not cached or has expired
+func (rl *rateLimiter) cachedAPIKey(hash string) (*schema.APIKey, bool) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	cached, ok := rl.keys[hash]
+	if !ok || time.Now().After(cached.expires) {
+		return nil, false
+	}
+
+	return cached.key, true
+}
+
+// apiKey looks up the API key of the given hash in database and caches it, returning nil if it doesn't exist.
+// Keys that don't exist are not cached once maxMissingAPIKeys of them are cached
+func (rl *rateLimiter) apiKey(r *http.Request, hash string) (*schema.APIKey, error) {
+	key, err := rl.db.WithContext(r.Context()).QueryAPIKey(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	rl.deleteAPIKey(hash)
+
+	if key == nil {
+		if rl.missing >= maxMissingAPIKeys {
+			return nil, nil
+		}
+		rl.missing++
+	}
+
+	rl.keys[hash] = cachedAPIKey{key: key, expires: time.Now().Add(apiKeyTTL)}
+
+	return key, nil
+}
+
+// deleteAPIKey removes a cached API key. It must be called with the lock held
+func (rl *rateLimiter) deleteAPIKey(hash string) {
+	if cached, ok := rl.keys[hash]; ok {
+		if cached.key == nil {
+			rl.missing--
+		}
+		delete(rl.keys, hash)
+	}
+}
+
+// clientIP returns IP of the client. Behind proxies, it is the address that the outermost proxy
+// appended to X-Forwarded-For, since addresses before that can be forged by the client
+func (rl *rateLimiter) clientIP(r *http.Request) string {
+	if rl.cfg.ProxyHops > 0 {
+		forwarded := strings.Split(r.Header.Get("X-Forwarded-For"), ",")
+		if i := len(forwarded) - rl.cfg.ProxyHops; i >= 0 {
+			if ip := strings.TrimSpace(forwarded[i]); ip != "" {
+				return ip
+			}
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+// refill returns the time it takes to refill the given number of tokens
+func refill(tokens float64, rate float64) time.Duration {
+	if tokens <= 0 {
+		return 0
+	}
+
+	return time.Duration(tokens / rate * float64(time.Second))
+}
+
+// seconds rounds a duration up to whole seconds
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
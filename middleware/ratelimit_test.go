@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"testing"
+	"time"
+
+	"mintscan/config"
+)
+
+func TestRateLimiterTake(t *testing.T) {
+	tier := config.RateLimitTier{Rate: 2, Burst: 3}
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// Steps are taken in order from the same bucket
+	steps := []struct {
+		name       string
+		after      time.Duration // since start
+		tier       config.RateLimitTier
+		allowed    bool
+		remaining  int
+		reset      time.Duration
+		retryAfter time.Duration
+	}{
+		{"new bucket is full", 0, tier, true, 2, 500 * time.Millisecond, 0},
+		{"second token", 0, tier, true, 1, time.Second, 0},
+		{"last token", 0, tier, true, 0, 1500 * time.Millisecond, 500 * time.Millisecond},
+		{"empty bucket", 0, tier, false, 0, 1500 * time.Millisecond, 500 * time.Millisecond},
+		{"partially refilled", 250 * time.Millisecond, tier, false, 0, 1250 * time.Millisecond, 250 * time.Millisecond},
+		{"refilled a token", 500 * time.Millisecond, tier, true, 0, 1500 * time.Millisecond, 500 * time.Millisecond},
+		{"refill is capped at burst", 10 * time.Second, tier, true, 2, 500 * time.Millisecond, 0},
+		{"changed tier starts a new bucket", 10 * time.Second, config.RateLimitTier{Rate: 1, Burst: 1}, true, 0, time.Second, time.Second},
+	}
+
+	rl := &rateLimiter{buckets: make(map[string]*bucket)}
+
+	for _, step := range steps {
+		allowed, remaining, reset, retryAfter := rl.take("ip:127.0.0.1", step.tier, start.Add(step.after))
+		if allowed != step.allowed || remaining != step.remaining || reset != step.reset || retryAfter != step.retryAfter {
+			t.Fatalf("%s: take() = (%v, %d, %v, %v), want (%v, %d, %v, %v)", step.name,
+				allowed, remaining, reset, retryAfter, step.allowed, step.remaining, step.reset, step.retryAfter)
+		}
+	}
+
+	// Buckets of other keys are independent
+	if allowed, remaining, _, _ := rl.take("ip:127.0.0.2", tier, start); !allowed || remaining != 2 {
+		t.Errorf("take() of another key = (%v, %d), want (true, 2)", allowed, remaining)
+	}
+}
+
+func TestRefill(t *testing.T) {
+	tests := []struct {
+		tokens float64
+		rate   float64
+		want   time.Duration
+	}{
+		{0, 2, 0},
+		{-1, 2, 0},
+		{1, 2, 500 * time.Millisecond},
+		{3, 0.5, 6 * time.Second},
+		{0.25, 1, 250 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		if got := refill(tt.tokens, tt.rate); got != tt.want {
+			t.Errorf("refill(%v, %v) = %v, want %v", tt.tokens, tt.rate, got, tt.want)
+		}
+	}
+}
+
+func TestSeconds(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want int
+	}{
+		{0, 0},
+		{time.Millisecond, 1},
+		{time.Second, 1},
+		{1500 * time.Millisecond, 2},
+	}
+
+	for _, tt := range tests {
+		if got := seconds(tt.d); got != tt.want {
+			t.Errorf("seconds(%v) = %d, want %d", tt.d, got, tt.want)
+		}
+	}
+}
@@ -1,6 +1,10 @@
 package models
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
 
 // Account defines the structure for account information
 type Account struct {
@@ -17,6 +21,78 @@ type Account struct {
 	} `json:"balances"`
 }
 
+// AccountFlags define named capabilities of account flag bits
+var AccountFlags = []struct {
+	Flag uint64
+	Name string
+}{
+	{0x0000000000000001, "memo_required"}, // transfers to the account must have a memo
+}
+
+// Account types
+const (
+	AccountTypeStandard = "standard"
+	AccountTypeMultisig = "multisig"
+	AccountTypeScript   = "script"
+)
+
+// AccountFlagNames decodes account flags into named capabilities
+func AccountFlagNames(flags uint64) []string {
+	names := make([]string, 0)
+
+	for _, af := range AccountFlags {
+		if flags&af.Flag != 0 {
+			names = append(names, af.Name)
+			flags &^= af.Flag
+		}
+	}
+
+	for bit := uint(0); bit < 64; bit++ {
+		if flags&(1<<bit) != 0 {
+			names = append(names, fmt.Sprintf("unknown_0x%x", uint64(1)<<bit))
+		}
+	}
+
+	return names
+}
+
+type (
+	// ResultAccount defines the structure for account result response
+	ResultAccount struct {
+		Account
+		Label     string      `json:"label,omitempty"`
+		FlagNames []string    `json:"flag_names"`
+		Type      string      `json:"account_type"`
+		PubKey    *PubKeyInfo `json:"pub_key,omitempty"`
+	}
+
+	// PubKeyInfo wraps decoded account public key. Threshold and PubKeys are set
+	// only for multisig public keys
+	PubKeyInfo struct {
+		Type      string       `json:"type"`
+		Bech32    string       `json:"bech32"`
+		Threshold uint         `json:"threshold,omitempty"`
+		PubKeys   []PubKeyInfo `json:"pub_keys,omitempty"`
+	}
+
+	// ResultAccountFlags defines the structure for flag change history of an account
+	ResultAccountFlags struct {
+		Address   string        `json:"address"`
+		Flags     uint64        `json:"flags"`
+		FlagNames []string      `json:"flag_names"`
+		History   []FlagsChange `json:"history"`
+	}
+
+	// FlagsChange wraps account flags set by a transaction
+	FlagsChange struct {
+		Flags     uint64    `json:"flags"`
+		FlagNames []string  `json:"flag_names"`
+		Height    int64     `json:"height"`
+		TxHash    string    `json:"tx_hash"`
+		Timestamp time.Time `json:"timestamp"`
+	}
+)
+
 // AccountTxs defines the structure for asset transactions
 type AccountTxs struct {
 	TxNums  int `json:"txNums"`
@@ -61,6 +137,8 @@ type AccountTxArray struct {
 	TxFee         float64        `json:"txFee"`
 	FromAddr      string         `json:"fromAddr"`
 	ToAddr        string         `json:"toAddr,omitempty"`
+	FromAddrLabel string         `json:"fromAddrLabel,omitempty"`
+	ToAddrLabel   string         `json:"toAddrLabel,omitempty"`
 	TxAge         int64          `json:"txAge"`
 	OrderID       string         `json:"orderId,omitempty"`
 	Message       *AccountTxData `json:"message,omitempty"`
@@ -84,3 +162,26 @@ type AccountTxData struct {
 		OrderID     string `json:"orderId"`
 	} `json:"orderData"`
 }
+
+type (
+	// ResultTopAccounts defines the structure for the largest accounts of an asset
+	ResultTopAccounts struct {
+		Asset       string       `json:"asset"`
+		TotalSupply float64      `json:"total_supply"`
+		Accounts    []TopAccount `json:"accounts"`
+	}
+
+	// TopAccount wraps balance of an account in the largest accounts of an asset
+	TopAccount struct {
+		Rank      int       `json:"rank"`
+		Address   string    `json:"address"`
+		Label     string    `json:"label,omitempty"`
+		Free      float64   `json:"free"`
+		Locked    float64   `json:"locked"`
+		Frozen    float64   `json:"frozen"`
+		Total     float64   `json:"total"`
+		Share     float64   `json:"share"`                // percentage of total supply
+		Change24H *float64  `json:"change_24h,omitempty"` // omitted when balance 24 hours ago is not tracked
+		Timestamp time.Time `json:"timestamp"`
+	}
+)
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+type (
+	// APIKey defines the structure for an API key that is issued by admin.
+	// Key is only returned when the key is created since only its hash is saved
+	APIKey struct {
+		ID        int32     `json:"id"`
+		Key       string    `json:"key,omitempty"`
+		Name      string    `json:"name"`
+		Tier      string    `json:"tier"`
+		Disabled  bool      `json:"disabled"`
+		Timestamp time.Time `json:"timestamp"`
+	}
+
+	// ResultAPIKeys defines the structure for API keys result response
+	ResultAPIKeys struct {
+		Total   int      `json:"total"`
+		APIKeys []APIKey `json:"api_keys"`
+	}
+)
@@ -5,8 +5,8 @@ import (
 	"time"
 )
 
-// ChosenAssetNames define 4 asset names
-// that are displayed on the card view on Asset page
+// ChosenAssetNames define default asset names that are displayed on the card view on Asset page
+// when featured assets are neither saved in database nor configured
 var ChosenAssetNames = []string{
 	"TUSDB-888",
 	"USDSB-1AC",
@@ -158,6 +158,8 @@ type (
 		TxFee         float64      `json:"txFee"`
 		FromAddr      string       `json:"fromAddr"`
 		ToAddr        string       `json:"toAddr,omitempty"`
+		FromAddrLabel string       `json:"fromAddrLabel,omitempty"`
+		ToAddrLabel   string       `json:"toAddrLabel,omitempty"`
 		TxAge         int64        `json:"txAge"`
 		OrderID       string       `json:"orderId,omitempty"`
 		Message       *AssetTxData `json:"message,omitempty"`
@@ -183,6 +185,19 @@ type (
 	}
 )
 
+// ChartRanges define time ranges that asset chart history can be requested in
+var ChartRanges = map[string]time.Duration{
+	"24h": 24 * time.Hour,
+	"7d":  7 * 24 * time.Hour,
+	"30d": 30 * 24 * time.Hour,
+	"1y":  365 * 24 * time.Hour,
+}
+
+// FeaturedAssets defines the structure for featured asset list
+type FeaturedAssets struct {
+	Assets []string `json:"assets"`
+}
+
 type (
 	// AssetChartHistory defines the structure for asset chart hisotry
 	AssetChartHistory struct {
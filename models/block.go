@@ -13,6 +13,7 @@ type (
 	BlockData struct {
 		Height        int64     `json:"height"`
 		Proposer      string    `json:"proposer"`
+		ProposerLabel string    `json:"proposer_label,omitempty" sql:"-"`
 		Moniker       string    `json:"moniker"`
 		BlockHash     string    `json:"block_hash"`
 		ParentHash    string    `json:"parent_hash"`
@@ -20,6 +21,15 @@ type (
 		NumTxs        int64     `json:"num_txs" sql:"default:0"`
 		TotalTxs      int64     `json:"total_txs" sql:"default:0"`
 		Txs           []Txs     `json:"txs"`
+		Signers       []Signer  `json:"signers,omitempty" sql:"-"`
 		Timestamp     time.Time `json:"timestamp" sql:"default:now()"`
 	}
+
+	// Signer wraps a validator in the validator set of a block and whether it signed the block
+	Signer struct {
+		Address     string `json:"address"`
+		Moniker     string `json:"moniker,omitempty"`
+		VotingPower int64  `json:"voting_power"`
+		Signed      bool   `json:"signed"`
+	}
 )
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// CandleIntervals define intervals that candles are aggregated in
+var CandleIntervals = map[string]time.Duration{
+	"1m": time.Minute,
+	"5m": 5 * time.Minute,
+	"1h": time.Hour,
+	"1d": 24 * time.Hour,
+}
+
+type (
+	// ResultCandles defines the structure for candles result response
+	ResultCandles struct {
+		Symbol   string   `json:"symbol"`
+		Interval string   `json:"interval"`
+		Data     []Candle `json:"data"`
+	}
+
+	// Candle wraps OHLCV data in an interval
+	Candle struct {
+		OpenTime    time.Time `json:"open_time"`
+		Open        string    `json:"open"`
+		High        string    `json:"high"`
+		Low         string    `json:"low"`
+		Close       string    `json:"close"`
+		Volume      string    `json:"volume"`
+		QuoteVolume string    `json:"quote_volume"`
+		NumTrades   int64     `json:"num_trades"`
+	}
+)
@@ -0,0 +1,115 @@
+package models
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// HolderBalanceBuckets define lower bounds of balance size buckets that holders are counted in
+var HolderBalanceBuckets = []float64{0, 1, 10, 100, 1000, 10000, 100000, 1000000}
+
+type (
+	// AssetDistribution defines the structure for asset holder concentration and distribution
+	AssetDistribution struct {
+		Asset            string           `json:"asset"`
+		Holders          int              `json:"holders"`
+		SampledHolders   int              `json:"sampled_holders"` // number of top holders that concentration and buckets are computed from
+		Partial          bool             `json:"partial"`         // true when only the top holders are sampled
+		Gini             float64          `json:"gini"`
+		Top10Share       float64          `json:"top10_share"`
+		Top100Share      float64          `json:"top100_share"`
+		Buckets          []HolderBucket   `json:"buckets"`
+		HoldersChange24H int              `json:"holders_change_24h"`
+		HoldersChange7D  int              `json:"holders_change_7d"`
+		History          []HolderSnapshot `json:"history"`
+		UpdatedAt        time.Time        `json:"updated_at"` // time of the latest snapshot
+	}
+
+	// HolderBucket wraps number of holders whose balance is in a range
+	HolderBucket struct {
+		Min     float64 `json:"min"`
+		Max     float64 `json:"max,omitempty"` // unbounded when omitted
+		Holders int     `json:"holders"`
+	}
+
+	// HolderSnapshot wraps asset holder distribution at a point in time
+	HolderSnapshot struct {
+		Holders     int       `json:"holders"`
+		Gini        float64   `json:"gini"`
+		Top10Share  float64   `json:"top10_share"`
+		Top100Share float64   `json:"top100_share"`
+		Timestamp   time.Time `json:"timestamp"`
+	}
+)
+
+// NewAssetDistribution computes Gini coefficient, top holders share and balance size buckets
+// from quantities of the top holders out of the total number of holders. Top holders share is
+// computed against total supply when it is larger than the sampled quantities, while Gini
+// coefficient and buckets only cover the sampled holders
+func NewAssetDistribution(asset string, quantities []float64, holders int, supply float64) AssetDistribution {
+	sorted := make([]float64, len(quantities))
+	copy(sorted, quantities)
+	sort.Float64s(sorted)
+
+	buckets := make([]HolderBucket, len(HolderBalanceBuckets))
+	for i, min := range HolderBalanceBuckets {
+		buckets[i].Min = min
+		if i+1 < len(HolderBalanceBuckets) {
+			buckets[i].Max = HolderBalanceBuckets[i+1]
+		}
+	}
+
+	var total, weighted float64
+	for i, q := range sorted {
+		total += q
+		weighted += float64(i+1) * q
+
+		j := sort.SearchFloat64s(HolderBalanceBuckets, q)
+		if j == len(HolderBalanceBuckets) || HolderBalanceBuckets[j] != q {
+			j--
+		}
+		if j >= 0 {
+			buckets[j].Holders++
+		}
+	}
+
+	if holders < len(sorted) {
+		holders = len(sorted)
+	}
+
+	result := AssetDistribution{
+		Asset:          asset,
+		Holders:        holders,
+		SampledHolders: len(sorted),
+		Partial:        len(sorted) < holders,
+		Buckets:        buckets,
+		History:        []HolderSnapshot{},
+	}
+
+	if total <= 0 {
+		return result
+	}
+
+	n := float64(len(sorted))
+	result.Gini = 2*weighted/(n*total) - (n+1)/n
+
+	if supply > total {
+		total = supply
+	}
+
+	result.Top10Share = topShare(sorted, 10, total)
+	result.Top100Share = topShare(sorted, 100, total)
+
+	return result
+}
+
+// topShare returns the share of top n quantities in ascending sorted quantities in percent
+func topShare(sorted []float64, n int, total float64) float64 {
+	var sum float64
+	for i := len(sorted) - 1; i >= 0 && i >= len(sorted)-n; i-- {
+		sum += sorted[i]
+	}
+
+	return math.Round(sum/total*10000) / 100
+}
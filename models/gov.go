@@ -0,0 +1,98 @@
+package models
+
+import "time"
+
+// Vote options
+const (
+	VoteOptionYes        = "Yes"
+	VoteOptionAbstain    = "Abstain"
+	VoteOptionNo         = "No"
+	VoteOptionNoWithVeto = "NoWithVeto"
+)
+
+type (
+	// Proposal defines the structure for governance proposal API
+	Proposal struct {
+		Type  string        `json:"type"`
+		Value ProposalValue `json:"value"`
+	}
+
+	// ProposalValue wraps proposal state
+	ProposalValue struct {
+		ProposalID      int64       `json:"proposal_id,string"`
+		Title           string      `json:"title"`
+		Description     string      `json:"description"`
+		ProposalType    string      `json:"proposal_type"`
+		VotingPeriod    string      `json:"voting_period"`
+		Status          string      `json:"proposal_status"`
+		TallyResult     TallyResult `json:"tally_result"`
+		SubmitTime      time.Time   `json:"submit_time"`
+		TotalDeposit    []Coin      `json:"total_deposit"`
+		VotingStartTime time.Time   `json:"voting_start_time"`
+	}
+
+	// TallyResult wraps tally result reported by the chain
+	TallyResult struct {
+		Yes        string `json:"yes"`
+		Abstain    string `json:"abstain"`
+		No         string `json:"no"`
+		NoWithVeto string `json:"no_with_veto"`
+		Total      string `json:"total"`
+	}
+)
+
+type (
+	// ResultProposal defines the structure for proposal result response
+	ResultProposal struct {
+		ProposalValue
+		Proposer       string         `json:"proposer,omitempty"`
+		ProposerLabel  string         `json:"proposer_label,omitempty"`
+		SubmitTxHash   string         `json:"submit_tx_hash,omitempty"`
+		SubmitHeight   int64          `json:"submit_height,omitempty"`
+		ValidatorTally ValidatorTally `json:"validator_tally"`
+	}
+
+	// ValidatorTally wraps tally of the latest votes weighted by validator voting power
+	ValidatorTally struct {
+		Yes              int64 `json:"yes"`
+		Abstain          int64 `json:"abstain"`
+		No               int64 `json:"no"`
+		NoWithVeto       int64 `json:"no_with_veto"`
+		NotVoted         int64 `json:"not_voted"`
+		TotalVotingPower int64 `json:"total_voting_power"`
+	}
+
+	// ResultProposalVotes defines the structure for proposal votes result response
+	ResultProposalVotes struct {
+		ProposalID     int64          `json:"proposal_id"`
+		ValidatorTally ValidatorTally `json:"validator_tally"`
+		Votes          []ProposalVote `json:"votes"`
+	}
+
+	// ProposalVote wraps the latest vote of a voter
+	ProposalVote struct {
+		Voter       string    `json:"voter"`
+		Moniker     string    `json:"moniker,omitempty"`
+		Option      string    `json:"option"`
+		VotingPower int64     `json:"voting_power"`
+		Height      int64     `json:"height"`
+		TxHash      string    `json:"tx_hash"`
+		Timestamp   time.Time `json:"timestamp"`
+	}
+
+	// ResultProposalDeposits defines the structure for proposal deposits result response
+	ResultProposalDeposits struct {
+		ProposalID int64             `json:"proposal_id"`
+		Deposits   []ProposalDeposit `json:"deposits"`
+	}
+
+	// ProposalDeposit wraps deposit into a proposal
+	ProposalDeposit struct {
+		Depositor string    `json:"depositor"`
+		Amount    []Coin    `json:"amount"`
+		Initial   bool      `json:"initial,omitempty"` // initial deposit of the transaction that submitted the proposal
+		Height    int64     `json:"height"`
+		TxHash    string    `json:"tx_hash"`
+		Timestamp time.Time `json:"timestamp"`
+	}
+)
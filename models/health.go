@@ -0,0 +1,30 @@
+package models
+
+// Health check statuses
+const (
+	HealthStatusOK          = "ok"
+	HealthStatusUnavailable = "unavailable"
+)
+
+// ResultHealth defines the structure for liveness and readiness of the service
+type ResultHealth struct {
+	Status   string                   `json:"status"`
+	Networks map[string]NetworkHealth `json:"networks,omitempty"`
+}
+
+// NetworkHealth defines the structure for readiness of a network with the result of each dependency check
+type NetworkHealth struct {
+	Status   string                     `json:"status"`
+	Optional bool                       `json:"optional,omitempty"` // optional networks don't fail readiness
+	Checks   map[string]DependencyCheck `json:"checks"`
+}
+
+// DependencyCheck defines the structure for the result of a dependency check
+type DependencyCheck struct {
+	Status    string `json:"status"`
+	Optional  bool   `json:"optional,omitempty"` // failing optional checks don't fail readiness
+	Cached    bool   `json:"cached,omitempty"`   // result of a previous probe that is reused, see upstreamCheckTTL
+	LatencyMs int64  `json:"latency_ms"`
+	Detail    string `json:"detail,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
@@ -0,0 +1,16 @@
+package models
+
+type (
+	// Label defines the structure for an address label that is imported by admin
+	Label struct {
+		Address  string `json:"address"`
+		Label    string `json:"label"`
+		Category string `json:"category,omitempty"`
+	}
+
+	// ResultLabels defines the structure for labels result response
+	ResultLabels struct {
+		Total  int     `json:"total"`
+		Labels []Label `json:"labels"`
+	}
+)
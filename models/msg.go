@@ -0,0 +1,161 @@
+package models
+
+// Message value structures for indexed transaction messages.
+// Messages are saved in amino JSON format, which encodes int64 values as strings.
+type (
+	// NewOrderMsgValue wraps dex/NewOrder message value
+	NewOrderMsgValue struct {
+		Sender      string `json:"sender"`
+		ID          string `json:"id"`
+		Symbol      string `json:"symbol"`
+		OrderType   int8   `json:"ordertype"`
+		Side        int8   `json:"side"`
+		Price       int64  `json:"price,string"`
+		Quantity    int64  `json:"quantity,string"`
+		TimeInForce int8   `json:"timeinforce"`
+	}
+
+	// CancelOrderMsgValue wraps dex/CancelOrder message value
+	CancelOrderMsgValue struct {
+		Sender string `json:"sender"`
+		Symbol string `json:"symbol"`
+		RefID  string `json:"refid"`
+	}
+
+	// Coin wraps amount of a denom in message value
+	Coin struct {
+		Denom  string `json:"denom"`
+		Amount int64  `json:"amount,string"`
+	}
+
+	// IssueMsgValue wraps tokens/IssueMsg message value
+	IssueMsgValue struct {
+		From        string `json:"from"`
+		Name        string `json:"name"`
+		Symbol      string `json:"symbol"`
+		TotalSupply int64  `json:"total_supply,string"`
+		Mintable    bool   `json:"mintable"`
+	}
+
+	// TokenAmountMsgValue wraps tokens/MintMsg, tokens/BurnMsg, tokens/FreezeMsg
+	// and tokens/UnfreezeMsg message values
+	TokenAmountMsgValue struct {
+		From   string `json:"from"`
+		Symbol string `json:"symbol"`
+		Amount int64  `json:"amount,string"`
+	}
+
+	// TimeLockMsgValue wraps tokens/TimeLockMsg, tokens/TimeRelockMsg and tokens/TimeUnlockMsg message values
+	TimeLockMsgValue struct {
+		From        string `json:"from"`
+		ID          int64  `json:"time_lock_id,string"`
+		Description string `json:"description"`
+		Amount      []Coin `json:"amount"`
+		LockTime    int64  `json:"lock_time,string"`
+	}
+
+	// HTLTMsgValue wraps tokens/HTLTMsg message value
+	HTLTMsgValue struct {
+		From                string `json:"from"`
+		To                  string `json:"to"`
+		RecipientOtherChain string `json:"recipient_other_chain"`
+		SenderOtherChain    string `json:"sender_other_chain"`
+		RandomNumberHash    string `json:"random_number_hash"`
+		Timestamp           int64  `json:"timestamp,string"`
+		Amount              []Coin `json:"amount"`
+		ExpectedIncome      string `json:"expected_income"`
+		HeightSpan          int64  `json:"height_span,string"`
+		CrossChain          bool   `json:"cross_chain"`
+	}
+
+	// HTLTActionMsgValue wraps tokens/DepositHTLTMsg, tokens/ClaimHTLTMsg and tokens/RefundHTLTMsg message values
+	HTLTActionMsgValue struct {
+		From         string `json:"from"`
+		SwapID       string `json:"swap_id"`
+		Amount       []Coin `json:"amount"`
+		RandomNumber string `json:"random_number"`
+	}
+
+	// SubmitProposalMsgValue wraps cosmos-sdk/MsgSubmitProposal message value
+	SubmitProposalMsgValue struct {
+		Title          string `json:"title"`
+		Description    string `json:"description"`
+		ProposalType   string `json:"proposal_type"`
+		Proposer       string `json:"proposer"`
+		InitialDeposit []Coin `json:"initial_deposit"`
+		VotingPeriod   int64  `json:"voting_period,string"`
+	}
+
+	// ProposalDepositMsgValue wraps cosmos-sdk/MsgDeposit message value
+	ProposalDepositMsgValue struct {
+		ProposalID int64  `json:"proposal_id,string"`
+		Depositer  string `json:"depositer"`
+		Amount     []Coin `json:"amount"`
+	}
+
+	// VoteMsgValue wraps cosmos-sdk/MsgVote message value
+	VoteMsgValue struct {
+		ProposalID int64  `json:"proposal_id,string"`
+		Voter      string `json:"voter"`
+		Option     string `json:"option"`
+	}
+
+	// CreateValidatorMsgValue wraps cosmos-sdk/MsgCreateValidator message value
+	CreateValidatorMsgValue struct {
+		Description Description `json:"Description"`
+		Commission  struct {
+			Rate          string `json:"rate"`
+			MaxRate       string `json:"max_rate"`
+			MaxChangeRate string `json:"max_change_rate"`
+		} `json:"Commission"`
+		DelegatorAddress string `json:"delegator_address"`
+		ValidatorAddress string `json:"validator_address"`
+		Delegation       Coin   `json:"delegation"`
+	}
+
+	// CreateValidatorProposalMsgValue wraps cosmos-sdk/MsgCreateValidatorProposal message value
+	CreateValidatorProposalMsgValue struct {
+		MsgCreateValidator CreateValidatorMsgValue `json:"MsgCreateValidator"`
+		ProposalID         int64                   `json:"proposal_id,string"`
+	}
+
+	// RemoveValidatorMsgValue wraps cosmos-sdk/MsgRemoveValidator message value
+	RemoveValidatorMsgValue struct {
+		LauncherAddr string `json:"launcher_addr"`
+		ValAddr      string `json:"val_addr"`
+		ValConsAddr  string `json:"val_cons_addr"`
+		ProposalID   int64  `json:"proposal_id,string"`
+	}
+
+	// EditValidatorMsgValue wraps cosmos-sdk/MsgEditValidator message value.
+	// Description fields that are not edited are set to DoNotModifyDesc and
+	// CommissionRate is nil when the rate is not changed
+	EditValidatorMsgValue struct {
+		Description      Description `json:"Description"`
+		ValidatorAddress string      `json:"address"`
+		CommissionRate   *string     `json:"commission_rate"`
+	}
+
+	// UnjailMsgValue wraps cosmos-sdk/MsgUnjail message value
+	UnjailMsgValue struct {
+		ValidatorAddress string `json:"address"`
+	}
+
+	// SetAccountFlagsMsgValue wraps scripts/SetAccountFlagsMsg message value
+	SetAccountFlagsMsgValue struct {
+		From  string `json:"from"`
+		Flags uint64 `json:"flags,string"`
+	}
+
+	// SendMsgValue wraps cosmos-sdk/Send message value
+	SendMsgValue struct {
+		Inputs  []SendIO `json:"inputs"`
+		Outputs []SendIO `json:"outputs"`
+	}
+
+	// SendIO wraps input or output of cosmos-sdk/Send message value
+	SendIO struct {
+		Address string `json:"address"`
+		Coins   []Coin `json:"coins"`
+	}
+)
@@ -0,0 +1,61 @@
+package models
+
+import "time"
+
+// NetworkStatIntervals define intervals that network statistics are aggregated in
+var NetworkStatIntervals = map[string]time.Duration{
+	"1h": time.Hour,
+	"1d": 24 * time.Hour,
+}
+
+// NetworkMetrics define metrics of network statistics
+var NetworkMetrics = []string{
+	"txs",
+	"active_addresses",
+	"new_addresses",
+	"msg_types",
+	"est_avg_fee",
+	"avg_block_time",
+	"total_gas",
+}
+
+// FeeMsgTypes map message types to their names in fee parameters. Trading fees of orders are
+// charged when orders are matched and are not included in transaction fees
+var FeeMsgTypes = map[string]string{
+	"cosmos-sdk/Send":                       "send",
+	"cosmos-sdk/MsgSubmitProposal":          "submit_proposal",
+	"cosmos-sdk/MsgDeposit":                 "deposit",
+	"cosmos-sdk/MsgCreateValidatorProposal": "create_validator",
+	"cosmos-sdk/MsgRemoveValidator":         "remove_validator",
+	"dex/ListMsg":                           "dexList",
+	"tokens/IssueMsg":                       "issueMsg",
+	"tokens/MintMsg":                        "mintMsg",
+	"tokens/BurnMsg":                        "tokensBurn",
+	"tokens/FreezeMsg":                      "tokensFreeze",
+	"tokens/UnfreezeMsg":                    "tokensFreeze",
+	"tokens/TimeLockMsg":                    "timeLock",
+	"tokens/TimeUnlockMsg":                  "timeUnlock",
+	"tokens/TimeRelockMsg":                  "timeRelock",
+	"tokens/HTLTMsg":                        "HTLT",
+	"tokens/DepositHTLTMsg":                 "depositHTLT",
+	"tokens/ClaimHTLTMsg":                   "claimHTLT",
+	"tokens/RefundHTLTMsg":                  "refundHTLT",
+	"scripts/SetAccountFlagsMsg":            "setAccountFlags",
+}
+
+type (
+	// ResultNetworkStats defines the structure for network statistics result response
+	ResultNetworkStats struct {
+		Metric   string             `json:"metric"`
+		Interval string             `json:"interval"`
+		Data     []NetworkStatPoint `json:"data"`
+	}
+
+	// NetworkStatPoint wraps a metric value in an interval. Metrics that break down
+	// into message types are returned in values
+	NetworkStatPoint struct {
+		Time   time.Time        `json:"time"`
+		Value  float64          `json:"value"`
+		Values map[string]int64 `json:"values,omitempty"`
+	}
+)
@@ -22,3 +22,48 @@ type Order struct {
 	LastExecutedQuantity string    `json:"lastExecutedQuantity"`
 	TransactionHash      string    `json:"transactionHash"`
 }
+
+// Order status values returned by the accelerated node
+const (
+	OrderStatusAck            = "Ack"
+	OrderStatusPartialFill    = "PartialFill"
+	OrderStatusIocNoFill      = "IocNoFill"
+	OrderStatusFullyFill      = "FullyFill"
+	OrderStatusCanceled       = "Canceled"
+	OrderStatusExpired        = "Expired"
+	OrderStatusFailedBlocking = "FailedBlocking"
+	OrderStatusFailedMatching = "FailedMatching"
+	OrderStatusIocExpire      = "IocExpire"
+	OrderStatusUnknown        = "Unknown"
+)
+
+// Order side values
+const (
+	OrderSideBuy  = 1
+	OrderSideSell = 2
+)
+
+// IsOpen returns true when an order can still be matched
+func (o Order) IsOpen() bool {
+	return o.Status == OrderStatusAck || o.Status == OrderStatusPartialFill
+}
+
+type (
+	// ResultAccountOrders defines the structure for orders placed by an account
+	ResultAccountOrders struct {
+		Total  *int           `json:"total,omitempty"` // number of orders matching address and symbol, omitted with status filter
+		Before int32          `json:"before"`          // pass as before to get the next page, 0 when there are no more orders
+		Data   []AccountOrder `json:"data"`
+	}
+
+	// AccountOrder wraps order information with the transactions that created and canceled it
+	AccountOrder struct {
+		Order
+		OwnerLabel       string `json:"ownerLabel,omitempty"`
+		AvgExecutedPrice string `json:"avgExecutedPrice"`
+		CreateTxHash     string `json:"createTxHash"`
+		CreateHeight     int64  `json:"createHeight"`
+		CancelTxHash     string `json:"cancelTxHash,omitempty"`
+		CancelHeight     int64  `json:"cancelHeight,omitempty"`
+	}
+)
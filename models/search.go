@@ -0,0 +1,27 @@
+package models
+
+// Search result types
+const (
+	SearchTypeBlock     = "block"
+	SearchTypeTx        = "tx"
+	SearchTypeAccount   = "account"
+	SearchTypeValidator = "validator"
+	SearchTypeOrder     = "order"
+	SearchTypeAsset     = "asset"
+)
+
+type (
+	// ResultSearch defines the structure for search result response
+	ResultSearch struct {
+		Query   string         `json:"query"`
+		Results []SearchResult `json:"results"`
+	}
+
+	// SearchResult wraps a typed search result. Results with higher score rank first
+	SearchResult struct {
+		Type  string `json:"type"`
+		Value string `json:"value"`
+		Name  string `json:"name,omitempty"`
+		Score int    `json:"score"`
+	}
+)
@@ -4,9 +4,16 @@ import "time"
 
 // Status defines the structure for current status on the active chain
 type Status struct {
-	ChainID           string    `json:"chain_id"`
-	BlockTime         float64   `json:"block_time"`
-	LatestBlockHeight int64     `json:"latest_block_height"`
-	TotalValidatorNum int       `json:"total_validator_num"`
-	Timestamp         time.Time `json:"timestamp"`
+	ChainID            string    `json:"chain_id"`
+	BlockTime          float64   `json:"block_time"`
+	BlockTimeWindow    int64     `json:"block_time_window"`
+	TxsPerSecond       float64   `json:"txs_per_second"`
+	LatestBlockHeight  int64     `json:"latest_block_height"`
+	IndexedBlockHeight int64     `json:"indexed_block_height"`
+	IndexerLag         int64     `json:"indexer_lag"`
+	TotalValidatorNum  int       `json:"total_validator_num"`
+	BondedTokens       float64   `json:"bonded_tokens"`
+	Degraded           bool      `json:"degraded"`
+	DegradedSources    []string  `json:"degraded_sources,omitempty"` // sources that failed and whose data is missing
+	Timestamp          time.Time `json:"timestamp"`
 }
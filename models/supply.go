@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// Supply event types
+const (
+	SupplyEventIssue      = "issue"
+	SupplyEventMint       = "mint"
+	SupplyEventBurn       = "burn"
+	SupplyEventFreeze     = "freeze"
+	SupplyEventUnfreeze   = "unfreeze"
+	SupplyEventTimeLock   = "time_lock"
+	SupplyEventTimeRelock = "time_relock"
+	SupplyEventTimeUnlock = "time_unlock"
+)
+
+type (
+	// ResultSupplyEvents defines the structure for supply-changing events of an asset
+	ResultSupplyEvents struct {
+		Asset            string        `json:"asset"`
+		TotalSupply      float64       `json:"total_supply"`       // running total supply after the last event
+		TokenTotalSupply float64       `json:"token_total_supply"` // total supply reported by API server
+		Difference       float64       `json:"difference"`
+		Reconciled       bool          `json:"reconciled"`
+		Paging           Paging        `json:"paging"`
+		Events           []SupplyEvent `json:"events"` // the latest events first
+	}
+
+	// SupplyEvent wraps a supply-changing event with running totals after the event
+	SupplyEvent struct {
+		ID          int32     `json:"id"`
+		Type        string    `json:"type"`
+		Height      int64     `json:"height"`
+		TxHash      string    `json:"tx_hash"`
+		From        string    `json:"from"`
+		FromLabel   string    `json:"from_label,omitempty"`
+		Amount      float64   `json:"amount"`
+		TotalSupply float64   `json:"total_supply"`
+		Frozen      float64   `json:"frozen"`
+		Locked      float64   `json:"locked"`
+		Timestamp   time.Time `json:"timestamp"`
+	}
+)
@@ -0,0 +1,58 @@
+package models
+
+import "time"
+
+// Atomic swap status values
+const (
+	SwapStatusOpen     = "open"
+	SwapStatusClaimed  = "claimed"
+	SwapStatusRefunded = "refunded"
+	SwapStatusExpired  = "expired" // open but can be refunded since its height span has passed
+)
+
+type (
+	// ResultSwaps defines the structure for atomic swaps result response
+	ResultSwaps struct {
+		Paging Paging `json:"paging"`
+		Data   []Swap `json:"data"`
+	}
+
+	// Swap wraps atomic swap information with its deposits
+	Swap struct {
+		ID                  int32         `json:"id"`
+		SwapID              string        `json:"swap_id"`
+		From                string        `json:"from"`
+		FromLabel           string        `json:"from_label,omitempty"`
+		To                  string        `json:"to"`
+		ToLabel             string        `json:"to_label,omitempty"`
+		RecipientOtherChain string        `json:"recipient_other_chain"`
+		SenderOtherChain    string        `json:"sender_other_chain"`
+		RandomNumberHash    string        `json:"random_number_hash"`
+		RandomNumber        string        `json:"random_number,omitempty"`
+		SwapTimestamp       int64         `json:"swap_timestamp"`
+		Amount              []Coin        `json:"amount"`
+		ExpectedIncome      string        `json:"expected_income"`
+		HeightSpan          int64         `json:"height_span"`
+		ExpireHeight        int64         `json:"expire_height"`
+		CrossChain          bool          `json:"cross_chain"`
+		Status              string        `json:"status"`
+		Height              int64         `json:"height"`
+		TxHash              string        `json:"tx_hash"`
+		ClaimTxHash         string        `json:"claim_tx_hash,omitempty"`
+		ClaimHeight         int64         `json:"claim_height,omitempty"`
+		RefundTxHash        string        `json:"refund_tx_hash,omitempty"`
+		RefundHeight        int64         `json:"refund_height,omitempty"`
+		Deposits            []SwapDeposit `json:"deposits"`
+		Timestamp           time.Time     `json:"timestamp"`
+	}
+
+	// SwapDeposit wraps deposit into an atomic swap
+	SwapDeposit struct {
+		From      string    `json:"from"`
+		FromLabel string    `json:"from_label,omitempty"`
+		Amount    []Coin    `json:"amount"`
+		Height    int64     `json:"height"`
+		TxHash    string    `json:"tx_hash"`
+		Timestamp time.Time `json:"timestamp"`
+	}
+)
@@ -0,0 +1,72 @@
+package models
+
+import "time"
+
+// Time lock status values
+const (
+	TimeLockStatusLocked   = "locked"
+	TimeLockStatusUnlocked = "unlocked"
+)
+
+// TimeLock defines the structure for a time lock reconstructed from time lock messages
+type TimeLock struct {
+	ID           int64     `json:"id"`
+	From         string    `json:"from"`
+	Description  string    `json:"description"`
+	Amount       []Coin    `json:"amount"`
+	LockTime     time.Time `json:"lock_time"` // locked amount can be unlocked after lock time
+	Unlockable   bool      `json:"unlockable"`
+	Status       string    `json:"status"`
+	CreateTxHash string    `json:"create_tx_hash"`
+	CreateHeight int64     `json:"create_height"`
+	CreateTime   time.Time `json:"create_time"`
+	UnlockTxHash string    `json:"unlock_tx_hash,omitempty"`
+	UnlockHeight int64     `json:"unlock_height,omitempty"`
+}
+
+type (
+	// ResultTimeLocks defines the structure for time locks of an account
+	ResultTimeLocks struct {
+		Address   string          `json:"address"`
+		Totals    []TimeLockTotal `json:"totals"`
+		TimeLocks []TimeLock      `json:"time_locks"`
+	}
+
+	// TimeLockTotal wraps total amount of a denom in active time locks cross-checked
+	// against locked balance of the account
+	TimeLockTotal struct {
+		Denom         string  `json:"denom"`
+		TimeLocked    float64 `json:"time_locked"`
+		AccountLocked float64 `json:"account_locked"`
+		Difference    float64 `json:"difference"`
+	}
+)
+
+// SubCoins returns a minus b per denom, leaving out denoms whose difference is zero
+func SubCoins(a []Coin, b []Coin) []Coin {
+	amounts := make(map[string]int64)
+	denoms := make([]string, 0)
+
+	for _, coin := range a {
+		if _, ok := amounts[coin.Denom]; !ok {
+			denoms = append(denoms, coin.Denom)
+		}
+		amounts[coin.Denom] += coin.Amount
+	}
+
+	for _, coin := range b {
+		if _, ok := amounts[coin.Denom]; !ok {
+			denoms = append(denoms, coin.Denom)
+		}
+		amounts[coin.Denom] -= coin.Amount
+	}
+
+	result := make([]Coin, 0)
+	for _, denom := range denoms {
+		if amounts[denom] != 0 {
+			result = append(result, Coin{Denom: denom, Amount: amounts[denom]})
+		}
+	}
+
+	return result
+}
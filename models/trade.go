@@ -0,0 +1,26 @@
+package models
+
+// Trades defines the structure for trades API
+type Trades struct {
+	Total int     `json:"total"`
+	Trade []Trade `json:"trade"`
+}
+
+// Trade defines the structure for trade information
+type Trade struct {
+	TradeID       string `json:"tradeId"`
+	BlockHeight   int64  `json:"blockHeight"`
+	Symbol        string `json:"symbol"`
+	Price         string `json:"price"`
+	Quantity      string `json:"quantity"`
+	BuyerOrderID  string `json:"buyerOrderId"`
+	SellerOrderID string `json:"sellerOrderId"`
+	BuyerID       string `json:"buyerId"`
+	SellerID      string `json:"sellerId"`
+	BuyFee        string `json:"buyFee"`
+	SellFee       string `json:"sellFee"`
+	BaseAsset     string `json:"baseAsset"`
+	QuoteAsset    string `json:"quoteAsset"`
+	TickType      string `json:"tickType"`
+	Time          int64  `json:"time"`
+}
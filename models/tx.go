@@ -31,17 +31,18 @@ type (
 		Data   []TxData `json:"data"`
 	}
 
-	// TxData wraps tx data
+	// TxData wraps tx data. Labels are keyed by labeled addresses that appear in the tx
 	TxData struct {
-		ID         int32       `json:"id,omitempty"`
-		Height     int64       `json:"height"`
-		Result     bool        `json:"result"`
-		TxHash     string      `json:"tx_hash"`
-		Messages   []Message   `json:"messages"`
-		Signatures []Signature `json:"signatures"`
-		Memo       string      `json:"memo"`
-		Code       uint32      `json:"code"`
-		Timestamp  time.Time   `json:"timestamp"`
+		ID         int32             `json:"id,omitempty"`
+		Height     int64             `json:"height"`
+		Result     bool              `json:"result"`
+		TxHash     string            `json:"tx_hash"`
+		Messages   []Message         `json:"messages"`
+		Signatures []Signature       `json:"signatures"`
+		Memo       string            `json:"memo"`
+		Code       uint32            `json:"code"`
+		Labels     map[string]string `json:"labels,omitempty"`
+		Timestamp  time.Time         `json:"timestamp"`
 	}
 
 	// Signature wraps tx signature
@@ -3,6 +3,8 @@ package models
 import (
 	"encoding/json"
 	"time"
+
+	"mintscan/schema"
 )
 
 type (
@@ -42,6 +44,21 @@ type (
 	}
 )
 
+// DoNotModifyDesc is the description field value of MsgEditValidator that is left unchanged
+const DoNotModifyDesc = "[do-not-modify]"
+
+// Edited returns true if any field of the edited description is changed
+func (d Description) Edited() bool {
+	return d.Moniker != DoNotModifyDesc || d.Identity != DoNotModifyDesc ||
+		d.Website != DoNotModifyDesc || d.Details != DoNotModifyDesc
+}
+
+// ResultValidator defines the structure for validator response
+type ResultValidator struct {
+	*schema.Validator
+	Label string `json:"label,omitempty"`
+}
+
 // ResultValidators defines the structure for validators response
 type ResultValidators struct {
 	Moniker          string    `json:"moniker"`
@@ -50,3 +67,27 @@ type ResultValidators struct {
 	VotingPower      int64     `json:"voting_power"`
 	Timestamp        time.Time `json:"timestamp" sql:"default:now()"`
 }
+
+// Validator event types
+const (
+	ValidatorEventCreate           = "create"
+	ValidatorEventCreateProposal   = "create_proposal"
+	ValidatorEventRemove           = "remove"
+	ValidatorEventJail             = "jail"
+	ValidatorEventUnjail           = "unjail"
+	ValidatorEventCommissionChange = "commission_change"
+	ValidatorEventDescriptionEdit  = "description_edit"
+)
+
+// ValidatorEvent defines the structure for a validator lifecycle event.
+// Jail events are derived from validator snapshots, so they don't have tx hash
+// and their height is the height at which the change was observed
+type ValidatorEvent struct {
+	Type       string    `json:"type"`
+	Height     int64     `json:"height"`
+	TxHash     string    `json:"tx_hash,omitempty"`
+	ProposalID int64     `json:"proposal_id,omitempty"`
+	Old        string    `json:"old,omitempty"`
+	New        string    `json:"new,omitempty"`
+	Timestamp  time.Time `json:"timestamp"`
+}
@@ -0,0 +1,25 @@
+package schema
+
+import "time"
+
+// AccountBalance defines the schema for the latest balance of an asset held by an account
+type AccountBalance struct {
+	ID        int32     `json:"id" sql:",pk"`
+	Address   string    `json:"address" sql:",notnull,unique:address_asset"`
+	Asset     string    `json:"asset" sql:",notnull,unique:address_asset"`
+	Free      float64   `json:"free" sql:",notnull"`
+	Locked    float64   `json:"locked" sql:",notnull"`
+	Frozen    float64   `json:"frozen" sql:",notnull"`
+	Total     float64   `json:"total" sql:",notnull"`
+	Timestamp time.Time `json:"timestamp" sql:"default:now()"`
+}
+
+// AccountBalanceHistory defines the schema for changes of account balances.
+// A row is saved whenever total balance of an asset held by an account changes
+type AccountBalanceHistory struct {
+	ID        int32     `json:"id" sql:",pk"`
+	Address   string    `json:"address" sql:",notnull"`
+	Asset     string    `json:"asset" sql:",notnull"`
+	Total     float64   `json:"total" sql:",notnull"`
+	Timestamp time.Time `json:"timestamp" sql:"default:now()"`
+}
@@ -0,0 +1,11 @@
+package schema
+
+import "time"
+
+// Address defines the schema for addresses that have signed or received transactions, with the time they were first seen
+type Address struct {
+	ID        int32     `json:"id" sql:",pk"`
+	Address   string    `json:"address" sql:",notnull,unique"`
+	Height    int64     `json:"height" sql:",notnull"`
+	FirstSeen time.Time `json:"first_seen" sql:",notnull"`
+}
@@ -0,0 +1,14 @@
+package schema
+
+import "time"
+
+// APIKey defines the schema for API keys that lift rate limits of their holders to the limits of their tier.
+// Only SHA-256 hash of a key is saved
+type APIKey struct {
+	ID        int32     `json:"id" sql:",pk"`
+	KeyHash   string    `json:"key_hash" sql:",notnull,unique"`
+	Name      string    `json:"name" sql:",notnull"`
+	Tier      string    `json:"tier" sql:",notnull"`
+	Disabled  bool      `json:"disabled" sql:",notnull,default:false"`
+	Timestamp time.Time `json:"timestamp" sql:"default:now()"`
+}
@@ -0,0 +1,16 @@
+package schema
+
+import "time"
+
+// AssetHolderSnapshot defines the schema for periodic snapshots of asset holder distribution
+type AssetHolderSnapshot struct {
+	ID             int32     `json:"id" sql:",pk"`
+	Asset          string    `json:"asset" sql:",notnull"`
+	Holders        int       `json:"holders" sql:",notnull"`
+	SampledHolders int       `json:"sampled_holders" sql:",notnull"`
+	Gini           float64   `json:"gini" sql:",notnull"`
+	Top10Share     float64   `json:"top10_share" sql:",notnull"`
+	Top100Share    float64   `json:"top100_share" sql:",notnull"`
+	Buckets        string    `json:"buckets" sql:"type:jsonb, notnull, default: '[]'::jsonb"`
+	Timestamp      time.Time `json:"timestamp" sql:"default:now()"`
+}
@@ -0,0 +1,39 @@
+package schema
+
+import "time"
+
+// AtomicSwap defines the schema for atomic swap created by HTLT
+type AtomicSwap struct {
+	ID                  int32     `json:"id" sql:",pk"`
+	SwapID              string    `json:"swap_id" sql:",notnull,unique"`
+	From                string    `json:"from" sql:",notnull"`
+	To                  string    `json:"to" sql:",notnull"`
+	RecipientOtherChain string    `json:"recipient_other_chain"`
+	SenderOtherChain    string    `json:"sender_other_chain"`
+	RandomNumberHash    string    `json:"random_number_hash" sql:",notnull"`
+	RandomNumber        string    `json:"random_number"`
+	SwapTimestamp       int64     `json:"swap_timestamp" sql:",notnull"`
+	Amount              string    `json:"amount" sql:"type:jsonb, notnull, default: '[]'::jsonb"`
+	ExpectedIncome      string    `json:"expected_income"`
+	HeightSpan          int64     `json:"height_span" sql:",notnull"`
+	CrossChain          bool      `json:"cross_chain" sql:",notnull"`
+	Status              string    `json:"status" sql:",notnull"`
+	Height              int64     `json:"height" sql:",notnull"`
+	TxHash              string    `json:"tx_hash" sql:",notnull"`
+	ClaimTxHash         string    `json:"claim_tx_hash"`
+	ClaimHeight         int64     `json:"claim_height" sql:"default:0"`
+	RefundTxHash        string    `json:"refund_tx_hash"`
+	RefundHeight        int64     `json:"refund_height" sql:"default:0"`
+	Timestamp           time.Time `json:"timestamp" sql:"default:now()"`
+}
+
+// AtomicSwapDeposit defines the schema for deposit into an atomic swap
+type AtomicSwapDeposit struct {
+	ID        int32     `json:"id" sql:",pk"`
+	SwapID    string    `json:"swap_id" sql:",notnull,unique:swap_id_tx_hash"`
+	From      string    `json:"from" sql:",notnull"`
+	Amount    string    `json:"amount" sql:"type:jsonb, notnull, default: '[]'::jsonb"`
+	Height    int64     `json:"height" sql:",notnull"`
+	TxHash    string    `json:"tx_hash" sql:",notnull,unique:swap_id_tx_hash"`
+	Timestamp time.Time `json:"timestamp" sql:"default:now()"`
+}
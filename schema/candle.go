@@ -0,0 +1,18 @@
+package schema
+
+import "time"
+
+// Candle defines the schema for OHLCV candle aggregated from trades in a given interval
+type Candle struct {
+	ID          int32     `json:"id" sql:",pk"`
+	Symbol      string    `json:"symbol" sql:",notnull,unique:symbol_interval_open_time"`
+	Interval    string    `json:"interval" sql:",notnull,unique:symbol_interval_open_time"`
+	OpenTime    time.Time `json:"open_time" sql:",notnull,unique:symbol_interval_open_time"`
+	Open        string    `json:"open" sql:"type:numeric,notnull"`
+	High        string    `json:"high" sql:"type:numeric,notnull"`
+	Low         string    `json:"low" sql:"type:numeric,notnull"`
+	Close       string    `json:"close" sql:"type:numeric,notnull"`
+	Volume      string    `json:"volume" sql:"type:numeric,notnull"`
+	QuoteVolume string    `json:"quote_volume" sql:"type:numeric,notnull"`
+	NumTrades   int64     `json:"num_trades" sql:",notnull"`
+}
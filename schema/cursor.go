@@ -0,0 +1,11 @@
+package schema
+
+import "time"
+
+// Cursor defines the schema for position up to which an exporter job has processed
+type Cursor struct {
+	ID        int32     `json:"id" sql:",pk"`
+	Name      string    `json:"name" sql:",notnull,unique"`
+	Position  int64     `json:"position" sql:",notnull"`
+	Timestamp time.Time `json:"timestamp" sql:"default:now()"`
+}
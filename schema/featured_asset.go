@@ -0,0 +1,11 @@
+package schema
+
+import "time"
+
+// FeaturedAsset defines the schema for assets that are displayed on the card view on Asset page
+type FeaturedAsset struct {
+	ID        int32     `json:"id" sql:",pk"`
+	Asset     string    `json:"asset" sql:",notnull,unique"`
+	Position  int       `json:"position" sql:",notnull"`
+	Timestamp time.Time `json:"timestamp" sql:"default:now()"`
+}
@@ -0,0 +1,13 @@
+package schema
+
+import "time"
+
+// Label defines the schema for human readable labels of well-known addresses such as exchanges,
+// bridges, validators and burn addresses
+type Label struct {
+	ID        int32     `json:"id" sql:",pk"`
+	Address   string    `json:"address" sql:",notnull,unique"`
+	Label     string    `json:"label" sql:",notnull"`
+	Category  string    `json:"category"`
+	Timestamp time.Time `json:"timestamp" sql:"default:now()"`
+}
@@ -0,0 +1,18 @@
+package schema
+
+import "time"
+
+// NetworkStat defines the schema for network statistics aggregated from blocks and transactions in a given interval
+type NetworkStat struct {
+	ID              int32     `json:"id" sql:",pk"`
+	Interval        string    `json:"interval" sql:",notnull,unique:interval_time"`
+	Time            time.Time `json:"time" sql:",notnull,unique:interval_time"`
+	NumBlocks       int64     `json:"num_blocks" sql:",notnull"`
+	NumTxs          int64     `json:"num_txs" sql:",notnull"`
+	ActiveAddresses int64     `json:"active_addresses" sql:",notnull"`
+	NewAddresses    int64     `json:"new_addresses" sql:",notnull"`
+	MsgTypes        string    `json:"msg_types" sql:"type:jsonb, notnull, default: '{}'::jsonb"` // number of messages keyed by message type
+	EstAvgFee       float64   `json:"est_avg_fee" sql:",notnull"`                                // estimated with fee parameters at the time of aggregation
+	AvgBlockTime    float64   `json:"avg_block_time" sql:",notnull"`
+	TotalGas        int64     `json:"total_gas" sql:",notnull"`
+}
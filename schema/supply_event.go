@@ -0,0 +1,29 @@
+package schema
+
+import "time"
+
+// SupplyEvent defines the schema for a supply-changing event of an asset with running totals after the event.
+// Amounts are in 1e8 base units
+type SupplyEvent struct {
+	ID          int32     `json:"id" sql:",pk"`
+	Asset       string    `json:"asset" sql:",notnull"`
+	Type        string    `json:"type" sql:",notnull"`
+	Height      int64     `json:"height" sql:",notnull"`
+	TxID        int32     `json:"tx_id" sql:",notnull"`
+	TxHash      string    `json:"tx_hash" sql:",notnull"`
+	From        string    `json:"from" sql:",notnull"`
+	Amount      int64     `json:"amount" sql:",notnull"`
+	TotalSupply int64     `json:"total_supply" sql:",notnull"`
+	Frozen      int64     `json:"frozen" sql:",notnull"`
+	Locked      int64     `json:"locked" sql:",notnull"`
+	Timestamp   time.Time `json:"timestamp" sql:"default:now()"`
+}
+
+// TimeLock defines the schema for an active time lock of an account, which is needed to know
+// the amount released by a time unlock message. Time locks are deleted when they are unlocked
+type TimeLock struct {
+	ID      int32  `json:"id" sql:",pk"`
+	Address string `json:"address" sql:",notnull,unique:address_lock_id"`
+	LockID  int64  `json:"lock_id" sql:",notnull,unique:address_lock_id"`
+	Amount  string `json:"amount" sql:"type:jsonb, notnull, default: '[]'::jsonb"`
+}
@@ -0,0 +1,14 @@
+package schema
+
+import "time"
+
+// Trade defines the schema for trade information
+type Trade struct {
+	ID          int32     `json:"id" sql:",pk"`
+	TradeID     string    `json:"trade_id" sql:",notnull,unique"`
+	BlockHeight int64     `json:"block_height" sql:",notnull"`
+	Symbol      string    `json:"symbol" sql:",notnull"`
+	Price       string    `json:"price" sql:"type:numeric,notnull"`
+	Quantity    string    `json:"quantity" sql:"type:numeric,notnull"`
+	Timestamp   time.Time `json:"timestamp" sql:"default:now()"`
+}
@@ -0,0 +1,21 @@
+package schema
+
+import "time"
+
+// ValidatorSnapshot defines the schema for validator state that is saved whenever it changes
+type ValidatorSnapshot struct {
+	ID                      int32     `json:"id" sql:",pk"`
+	OperatorAddress         string    `json:"operator_address" sql:",notnull"`
+	Height                  int64     `json:"height" sql:",notnull"`
+	Jailed                  bool      `json:"jailed" sql:",notnull"`
+	Status                  string    `json:"status"`
+	Moniker                 string    `json:"moniker"`
+	Identity                string    `json:"identity"`
+	Website                 string    `json:"website"`
+	Details                 string    `json:"details"`
+	CommissionRate          string    `json:"commission_rate"`
+	CommissionMaxRate       string    `json:"commission_max_rate"`
+	CommissionMaxChangeRate string    `json:"commission_max_change_rate"`
+	CommissionUpdateTime    time.Time `json:"commission_update_time"`
+	Timestamp               time.Time `json:"timestamp" sql:"default:now()"`
+}
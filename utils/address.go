@@ -0,0 +1,79 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/binance-chain/go-sdk/common/bech32"
+	cmtypes "github.com/binance-chain/go-sdk/common/types"
+)
+
+// ValidatorAddrPrefixes returns bech32 prefixes of validator operator addresses on a network.
+// Testnet validators may use either tbva or bva prefix
+func ValidatorAddrPrefixes(nt cmtypes.ChainNetwork) []string {
+	if nt == cmtypes.TestNetwork {
+		return []string{"t" + nt.Bech32ValidatorAddrPrefix(), nt.Bech32ValidatorAddrPrefix()}
+	}
+
+	return []string{nt.Bech32ValidatorAddrPrefix()}
+}
+
+// IsAccAddress returns true if the address is a valid bech32 account address on a network
+func IsAccAddress(nt cmtypes.ChainNetwork, address string) bool {
+	hrp, bz, err := bech32.DecodeAndConvert(address)
+	return err == nil && hrp == nt.Bech32Prefixes() && len(bz) == cmtypes.AddrLen
+}
+
+// IsValAddress returns true if the address is a valid bech32 validator operator address on a network
+func IsValAddress(nt cmtypes.ChainNetwork, address string) bool {
+	hrp, bz, err := bech32.DecodeAndConvert(address)
+	if err != nil || len(bz) != cmtypes.AddrLen {
+		return false
+	}
+
+	for _, prefix := range ValidatorAddrPrefixes(nt) {
+		if hrp == prefix {
+			return true
+		}
+	}
+
+	return false
+}
+
+// EncodeAccAddresses re-encodes every bech32 account address in a JSON document with the prefix of a network.
+// Amino encodes addresses with the process-wide prefix of the go-sdk, which belongs to whichever network
+// created its RPC client last, so JSON of messages decoded from a node must be fixed up per network
+func EncodeAccAddresses(nt cmtypes.ChainNetwork, bz []byte) ([]byte, error) {
+	var v interface{}
+	d := json.NewDecoder(bytes.NewReader(bz))
+	d.UseNumber()
+	if err := d.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(encodeAccAddresses(nt, v))
+}
+
+func encodeAccAddresses(nt cmtypes.ChainNetwork, v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = encodeAccAddresses(nt, e)
+		}
+	case []interface{}:
+		for i, e := range v {
+			v[i] = encodeAccAddresses(nt, e)
+		}
+	case string:
+		hrp, bz, err := bech32.DecodeAndConvert(v)
+		if err != nil || len(bz) != cmtypes.AddrLen || (hrp != cmtypes.ProdNetwork.Bech32Prefixes() && hrp != cmtypes.TestNetwork.Bech32Prefixes()) {
+			return v
+		}
+
+		if address, err := bech32.ConvertAndEncode(nt.Bech32Prefixes(), bz); err == nil {
+			return address
+		}
+	}
+
+	return v
+}
@@ -0,0 +1,113 @@
+package utils
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/binance-chain/go-sdk/common/bech32"
+	cmtypes "github.com/binance-chain/go-sdk/common/types"
+)
+
+func encode(t *testing.T, hrp string, bz []byte) string {
+	address, err := bech32.ConvertAndEncode(hrp, bz)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return address
+}
+
+func TestIsAccAddress(t *testing.T) {
+	bz := bytes.Repeat([]byte{1}, cmtypes.AddrLen)
+
+	tests := []struct {
+		name    string
+		nt      cmtypes.ChainNetwork
+		address string
+		want    bool
+	}{
+		{"mainnet", cmtypes.ProdNetwork, encode(t, "bnb", bz), true},
+		{"testnet", cmtypes.TestNetwork, encode(t, "tbnb", bz), true},
+		{"testnet address on mainnet", cmtypes.ProdNetwork, encode(t, "tbnb", bz), false},
+		{"mainnet address on testnet", cmtypes.TestNetwork, encode(t, "bnb", bz), false},
+		{"validator address", cmtypes.ProdNetwork, encode(t, "bva", bz), false},
+		{"short address", cmtypes.ProdNetwork, encode(t, "bnb", bz[:10]), false},
+		{"invalid checksum", cmtypes.ProdNetwork, encode(t, "bnb", bz)[:40] + "qqqqqq", false},
+		{"empty", cmtypes.ProdNetwork, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsAccAddress(tt.nt, tt.address); got != tt.want {
+				t.Errorf("IsAccAddress(%d, %q) = %v, want %v", tt.nt, tt.address, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsValAddress(t *testing.T) {
+	bz := bytes.Repeat([]byte{1}, cmtypes.AddrLen)
+
+	tests := []struct {
+		name    string
+		nt      cmtypes.ChainNetwork
+		address string
+		want    bool
+	}{
+		{"mainnet", cmtypes.ProdNetwork, encode(t, "bva", bz), true},
+		{"testnet bva", cmtypes.TestNetwork, encode(t, "bva", bz), true},
+		{"testnet tbva", cmtypes.TestNetwork, encode(t, "tbva", bz), true},
+		{"tbva on mainnet", cmtypes.ProdNetwork, encode(t, "tbva", bz), false},
+		{"account address", cmtypes.ProdNetwork, encode(t, "bnb", bz), false},
+		{"short address", cmtypes.ProdNetwork, encode(t, "bva", bz[:10]), false},
+		{"empty", cmtypes.ProdNetwork, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsValAddress(tt.nt, tt.address); got != tt.want {
+				t.Errorf("IsValAddress(%d, %q) = %v, want %v", tt.nt, tt.address, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodeAccAddresses(t *testing.T) {
+	bz := bytes.Repeat([]byte{1}, cmtypes.AddrLen)
+	mainnet, testnet, validator := encode(t, "bnb", bz), encode(t, "tbnb", bz), encode(t, "bva", bz)
+
+	tests := []struct {
+		name string
+		nt   cmtypes.ChainNetwork
+		in   string
+		want string
+	}{
+		{"mainnet to testnet", cmtypes.TestNetwork,
+			`{"from":"` + mainnet + `","amount":100000000}`,
+			`{"amount":100000000,"from":"` + testnet + `"}`},
+		{"testnet to mainnet in arrays", cmtypes.ProdNetwork,
+			`{"outputs":[{"address":"` + testnet + `"}]}`,
+			`{"outputs":[{"address":"` + mainnet + `"}]}`},
+		{"same network", cmtypes.ProdNetwork,
+			`{"from":"` + mainnet + `"}`,
+			`{"from":"` + mainnet + `"}`},
+		{"validator address is kept", cmtypes.TestNetwork,
+			`{"validator":"` + validator + `"}`,
+			`{"validator":"` + validator + `"}`},
+		{"large numbers are kept", cmtypes.ProdNetwork,
+			`{"amount":9223372036854775807,"symbol":"BNB"}`,
+			`{"amount":9223372036854775807,"symbol":"BNB"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EncodeAccAddresses(tt.nt, []byte(tt.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("EncodeAccAddresses() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseBaseUnits parses a decimal amount with up to 8 decimal places into 1e8 base units
+// without the rounding errors of parsing it as a float
+func ParseBaseUnits(amount string) (int64, error) {
+	whole, fraction := amount, ""
+	if i := strings.Index(amount, "."); i >= 0 {
+		whole, fraction = amount[:i], amount[i+1:]
+	}
+
+	if len(fraction) > 8 {
+		return 0, fmt.Errorf("amount %s has more than 8 decimal places", amount)
+	}
+
+	units, err := strconv.ParseInt(whole+fraction+strings.Repeat("0", 8-len(fraction)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %s: %s", amount, err)
+	}
+
+	return units, nil
+}
@@ -0,0 +1,18 @@
+package utils
+
+import "encoding/json"
+
+// MsgFilter returns a JSON array that matches transaction messages of the given type
+// containing the given value. It is used with jsonb containment operator
+func MsgFilter(msgType string, value interface{}) string {
+	filter := []struct {
+		Type  string      `json:"type"`
+		Value interface{} `json:"value,omitempty"`
+	}{
+		{msgType, value},
+	}
+
+	bz, _ := json.Marshal(filter)
+
+	return string(bz)
+}
@@ -10,3 +10,10 @@ func Respond(w http.ResponseWriter, data interface{}) {
 	w.Header().Add("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(data)
 }
+
+// RespondWithStatus responds json format with any data type and the given status code
+func RespondWithStatus(w http.ResponseWriter, statusCode int, data interface{}) {
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(data)
+}